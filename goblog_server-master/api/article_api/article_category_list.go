@@ -1,14 +1,10 @@
 package article_api
 
 import (
-	"context"
-	"encoding/json"
 	"github.com/gin-gonic/gin"
-	"github.com/olivere/elastic/v7"
 	"github.com/sirupsen/logrus"
-	"gvb_server/global"
-	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service/article_ser"
 )
 
 type CategoryResponse struct {
@@ -24,35 +20,17 @@ type CategoryResponse struct {
 // @Produce json
 // @Success 200 {object} res.Response{data=[]CategoryResponse}
 func (ArticleApi) ArticleCategoryListView(c *gin.Context) {
-	type T struct {
-		DocCountErrorUpperBound int `json:"doc_count_error_upper_bound"`
-		SumOtherDocCount        int `json:"sum_other_doc_count"`
-		Buckets                 []struct {
-			Key      string `json:"key"`
-			DocCount int    `json:"doc_count"`
-		} `json:"buckets"`
-	}
-
-	agg := elastic.NewTermsAggregation().Field("category")
-	result, err := global.ESClient.
-		Search(models.ArticleModel{}.Index()).
-		Query(elastic.NewBoolQuery()).
-		Aggregation("categorys", agg).
-		Size(0).
-		Do(context.Background())
+	categories, err := article_ser.NewRepository().CategoryList()
 	if err != nil {
 		logrus.Error(err)
 		return
 	}
-	byteData := result.Aggregations["categorys"]
-	var categoryType T
-	_ = json.Unmarshal(byteData, &categoryType)
 
 	var categoryList = make([]CategoryResponse, 0)
-	for _, bucket := range categoryType.Buckets {
+	for _, category := range categories {
 		categoryList = append(categoryList, CategoryResponse{
-			Label: bucket.Key,
-			Value: bucket.Key,
+			Label: category,
+			Value: category,
 		})
 	}
 	res.OkWithData(categoryList, c)
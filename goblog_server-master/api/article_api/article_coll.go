@@ -5,6 +5,7 @@ import (
 	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service"
 	"gvb_server/service/es_ser"
 	"gvb_server/utils/jwts"
 )
@@ -57,6 +58,10 @@ func (ArticleApi) ArticleCollCreateView(c *gin.Context) {
 		"collects_count": model.CollectsCount + num,
 	})
 	if num == 1 {
+		err = service.ServiceApp.NotificationService.Collect(claims.UserID, model)
+		if err != nil {
+			global.Log.Error(err)
+		}
 		res.OkWithMessage("收藏文章成功", c)
 	} else {
 		res.OkWithMessage("取消收藏成功", c)
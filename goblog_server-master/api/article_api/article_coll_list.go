@@ -1,13 +1,11 @@
 package article_api
 
 import (
-	"context"
-	"encoding/json"
 	"github.com/gin-gonic/gin"
-	"github.com/olivere/elastic/v7"
 	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service/article_ser"
 	"gvb_server/service/common"
 	"gvb_server/utils/jwts"
 )
@@ -39,7 +37,7 @@ func (ArticleApi) ArticleCollListView(c *gin.Context) {
 	_claims, _ := c.Get("claims")
 	claims := _claims.(*jwts.CustomClaims)
 
-	var articleIDList []interface{}
+	var articleIDList []string
 
 	// 分页查询
 	list, count, err := common.ComList(models.UserCollectModel{UserID: claims.UserID}, common.Option{
@@ -53,32 +51,20 @@ func (ArticleApi) ArticleCollListView(c *gin.Context) {
 		collMap[model.ArticleID] = model.CreatedAt.Format("2006-01-02 15:04:05")
 	}
 
-	boolSearch := elastic.NewTermsQuery("_id", articleIDList...)
 	var collList = make([]CollResponse, 0)
 
-	// 传id列表，查询es
-	result, err := global.ESClient.
-		Search(models.ArticleModel{}.Index()).
-		Query(boolSearch).
-		Size(1000).
-		Do(context.Background())
+	// 传id列表，查询文章
+	articleList, err := article_ser.NewRepository().ListByIDList(articleIDList)
 	if err != nil {
 		res.FailWithMessage(err.Error(), c)
 		return
 	}
 
-	for _, hit := range result.Hits.Hits {
-		var article models.ArticleModel
-		err = json.Unmarshal(hit.Source, &article)
-		if err != nil {
-			global.Log.Error(err)
-			continue
-		}
-		article.ID = hit.Id
+	for _, article := range articleList {
 		article.Content = ""
 		collList = append(collList, CollResponse{
 			ArticleModel: article,
-			CreatedAt:    collMap[hit.Id],
+			CreatedAt:    collMap[article.ID],
 		})
 	}
 
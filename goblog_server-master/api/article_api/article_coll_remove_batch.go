@@ -1,14 +1,12 @@
 package article_api
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/olivere/elastic/v7"
 	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service/article_ser"
 	"gvb_server/service/es_ser"
 	"gvb_server/utils/jwts"
 )
@@ -45,31 +43,16 @@ func (ArticleApi) ArticleCollBatchRemoveView(c *gin.Context) {
 		return
 	}
 
-	var idList []interface{}
-	for _, s := range articleIDList {
-		idList = append(idList, s)
-	}
 	// 更新文章数
-	boolSearch := elastic.NewTermsQuery("_id", idList...)
-	result, err := global.ESClient.
-		Search(models.ArticleModel{}.Index()).
-		Query(boolSearch).
-		Size(1000).
-		Do(context.Background())
+	articleList, err := article_ser.NewRepository().ListByIDList(articleIDList)
 	if err != nil {
 		res.FailWithMessage(err.Error(), c)
 		return
 	}
-	for _, hit := range result.Hits.Hits {
-		var article models.ArticleModel
-		err = json.Unmarshal(hit.Source, &article)
-		if err != nil {
-			global.Log.Error(err)
-			continue
-		}
+	for _, article := range articleList {
 		count := article.CollectsCount - 1
 		// 更新文章收藏数
-		err = es_ser.ArticleUpdate(hit.Id, map[string]any{
+		err = es_ser.ArticleUpdate(article.ID, map[string]any{
 			"collects_count": count,
 		})
 		if err != nil {
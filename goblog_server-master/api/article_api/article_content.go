@@ -1,13 +1,10 @@
 package article_api
 
 import (
-	"context"
 	"github.com/gin-gonic/gin"
-	"github.com/goccy/go-json"
-	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/res"
-	"gvb_server/service/redis_ser"
+	"gvb_server/service/article_ser"
 )
 
 // ArticleContentView 获取文章正文
@@ -25,22 +22,16 @@ func (ArticleApi) ArticleContentView(c *gin.Context) {
 		res.FailWithCode(res.ArgumentError, c)
 		return
 	}
-	// 用户浏览量
-	redis_ser.NewArticleLook().Set(cr.ID)
-
-	result, err := global.ESClient.
-		Get().
-		Index(models.ArticleModel{}.Index()).
-		Id(cr.ID).
-		Do(context.Background())
+	model, err := article_ser.NewRepository().Detail(cr.ID)
 	if err != nil {
 		res.FailWithMessage("查询失败", c)
 		return
 	}
-	var model models.ArticleModel
-	err = json.Unmarshal(result.Source, &model)
-	if err != nil {
+	if !model.Status.IsPublished() && !IsAdmin(c) {
+		res.FailWithMessage("文章不存在", c)
 		return
 	}
+	// 用户浏览量
+	addArticleView(c, cr.ID)
 	res.OkWithData(model.Content, c)
 }
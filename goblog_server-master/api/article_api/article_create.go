@@ -1,18 +1,18 @@
 package article_api
 
 import (
-	md "github.com/JohannesKaufmann/html-to-markdown"
-	"github.com/PuerkitoBio/goquery"
+	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/russross/blackfriday"
 	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/ctype"
 	"gvb_server/models/res"
+	"gvb_server/service"
+	"gvb_server/service/article_ser"
 	"gvb_server/service/es_ser"
+	"gvb_server/service/redis_ser"
 	"gvb_server/utils/jwts"
 	"math/rand"
-	"strings"
 	"time"
 )
 
@@ -25,6 +25,9 @@ type ArticleRequest struct {
 	Link     string      `json:"link"`                                    // 原文链接
 	BannerID uint        `json:"banner_id"`                               // 文章封面ID
 	Tags     ctype.Array `json:"tags"`                                    // 文章标签
+
+	Status    ctype.ArticleStatus `json:"status"`     // 文章状态，不传默认直接发布
+	PublishAt string              `json:"publish_at"` // 定时发布时间 2006-01-02 15:04:05，发布时不传为当前时间，待发布不传时需要手动发布
 }
 
 // ArticleCreateView 创建文章
@@ -47,32 +50,14 @@ func (ArticleApi) ArticleCreateView(c *gin.Context) {
 	userID := claims.UserID
 	userNickName := claims.NickName
 	// 校验content 防xss攻击
-
-	// 处理content
-	unsafe := blackfriday.MarkdownCommon([]byte(cr.Content))
-	// 是否又script标签
-	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(string(unsafe)))
-	//fmt.Println(doc.Text())
-	nodes := doc.Find("script").Nodes
-	if len(nodes) > 0 {
+	content, text, filtered := service.ServiceApp.ArticleService.FilterXSS(cr.Content)
+	if filtered {
 		// 告警
-		global.Log.Warnf("XSS攻击已被过滤！用户id及用户名：%d/%s，内容：%s", userID, userNickName, unsafe)
-		// 有script标签
-		doc.Find("script").Remove()
-		converter := md.NewConverter("", true, nil)
-		html, _ := doc.Html()
-		markdown, _ := converter.ConvertString(html)
-		cr.Content = markdown
+		global.Log.Warnf("XSS攻击已被过滤！用户id及用户名：%d/%s，内容：%s", userID, userNickName, cr.Content)
 	}
+	cr.Content = content
 	if cr.Abstract == "" {
-		// 汉字的截取不一样
-		abs := []rune(doc.Text())
-		// 将Content转为html，并且过滤xss，以及获取中文内容
-		if len(abs) > 100 {
-			cr.Abstract = string(abs[:100])
-		} else {
-			cr.Abstract = string(abs)
-		}
+		cr.Abstract = service.ServiceApp.ArticleService.GetAbstract(text)
 	}
 	// 不传banner_id,后台随机选取一张
 	if cr.BannerID == 0 {
@@ -102,6 +87,22 @@ func (ArticleApi) ArticleCreateView(c *gin.Context) {
 		return
 	}
 
+	// 文章状态及定时发布
+	if cr.Status == 0 {
+		cr.Status = ctype.ArticlePublished
+	}
+	if !cr.Status.IsValid() {
+		res.FailWithMessage("文章状态错误", c)
+		return
+	}
+	publishAt, err := parsePublishAt(cr.PublishAt)
+	if err != nil {
+		res.FailWithMessage("发布时间格式错误", c)
+		return
+	}
+	// 发布时间在未来的文章先进入待发布，到点后由定时任务发布
+	status, publishAtValue := schedule(cr.Status, publishAt)
+
 	now := time.Now().Format("2006-01-02 15:04:05")
 	article := models.ArticleModel{
 		CreatedAt:    now,
@@ -119,20 +120,35 @@ func (ArticleApi) ArticleCreateView(c *gin.Context) {
 		BannerID:     cr.BannerID,
 		BannerUrl:    bannerUrl,
 		Tags:         cr.Tags,
+		Status:       status,
+		PublishAt:    publishAtValue,
 	}
 	// 判断文章标题是否存在
-	if article.ISExistData() {
+	repository := article_ser.NewRepository()
+	if repository.IsExistTitle(article.Title) {
 		res.FailWithMessage("文章已存在", c)
 		return
 	}
 
-	err = article.Create()
+	err = repository.Create(&article)
 	if err != nil {
 		global.Log.Error(err)
 		res.FailWithMessage(err.Error(), c)
 		return
 	}
+	// 文章变化，sitemap重新生成
+	redis_ser.ClearSitemap()
+	// 第一个修订版本
+	_, err = service.ServiceApp.ArticleService.CreateRevision(article, userID, userNickName, "创建文章")
+	if err != nil {
+		global.Log.Error(err)
+	}
 
+	// 只有已发布的文章才进入全文搜索
+	if article.Status != ctype.ArticlePublished {
+		res.OkWithData(fmt.Sprintf("文章已保存，当前状态：%s", article.Status), c)
+		return
+	}
 	go es_ser.AsyncArticleByFullText(article.ID, article.Title, article.Content)
 	res.OkWithData("文章发布成功", c)
 }
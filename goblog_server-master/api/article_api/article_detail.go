@@ -3,16 +3,19 @@ package article_api
 import (
 	"github.com/gin-gonic/gin"
 	"gvb_server/global"
+	"gvb_server/middleware"
 	"gvb_server/models"
+	"gvb_server/models/ctype"
 	"gvb_server/models/res"
 	"gvb_server/service/es_ser"
 	"gvb_server/service/redis_ser"
-	"gvb_server/utils/jwts"
+	"gvb_server/utils"
 )
 
 type ArticleDetailResponse struct {
 	models.ArticleModel
 	IsCollect bool `json:"is_collect"` // 用户是否收藏文章
+	IsDigg    bool `json:"is_digg"`    // 用户是否点赞文章
 }
 
 // ArticleDetailView 文章详情
@@ -30,36 +33,65 @@ func (ArticleApi) ArticleDetailView(c *gin.Context) {
 		res.FailWithCode(res.ArgumentError, c)
 		return
 	}
-	// 用户浏览量
-	redis_ser.NewArticleLook().Set(cr.ID)
-
 	model, err := es_ser.CommeDetail(cr.ID)
 	if err != nil {
 		res.FailWithMessage(err.Error(), c)
 		return
 	}
+	// 未发布的文章只有管理员能看
+	if !model.Status.IsPublished() && !IsAdmin(c) {
+		res.FailWithMessage("文章不存在", c)
+		return
+	}
+	// 用户浏览量
+	addArticleView(c, cr.ID)
+
 	isCollect := IsUserArticleColl(c, model.ID)
 
 	var articleDetail = ArticleDetailResponse{
 		ArticleModel: model,
 		IsCollect:    isCollect,
+		IsDigg:       IsUserArticleDigg(c, model.ID),
 	}
 
 	res.OkWithData(articleDetail, c)
 }
 
-func IsUserArticleColl(c *gin.Context, articleID string) (isCollect bool) {
-	// 查询用户是否正常登录
-	token := c.GetHeader("token")
-	if token == "" {
+// addArticleView 记录浏览量、访客和来源，爬虫不计
+// 前端拿到的是单页应用的跳转，真实来源通过referrer参数（document.referrer）传过来，没有再取Referer头
+func addArticleView(c *gin.Context, articleID string) {
+	userAgent := c.GetHeader("User-Agent")
+	if utils.IsBot(userAgent) {
 		return
 	}
-	claims, err := jwts.ParseToken(token)
+	var userID uint
+	if claims := middleware.GetClaims(c); claims != nil {
+		userID = claims.UserID
+	}
+	referrer := c.Query("referrer")
+	if referrer == "" {
+		referrer = c.GetHeader("Referer")
+	}
+	host, keyword := utils.ParseReferrer(referrer)
+	err := redis_ser.AddArticleView(articleID, utils.VisitorKey(userID, c.ClientIP(), userAgent), redis_ser.ViewSource{
+		Referrer: host,
+		Keyword:  keyword,
+	})
 	if err != nil {
-		return
+		global.Log.Error(err)
 	}
-	// 判断是否在redis中
-	if redis_ser.CheckLogout(token) {
+}
+
+// IsAdmin 当前请求是否由管理员发起
+func IsAdmin(c *gin.Context) bool {
+	claims := middleware.GetClaims(c)
+	return claims != nil && claims.Role == int(ctype.PermissionAdmin)
+}
+
+func IsUserArticleColl(c *gin.Context, articleID string) (isCollect bool) {
+	// 查询用户是否正常登录
+	claims := middleware.GetClaims(c)
+	if claims == nil {
 		return
 	}
 	var count int64
@@ -95,5 +127,9 @@ func (ArticleApi) ArticleDetailByTitleView(c *gin.Context) {
 		res.FailWithMessage(err.Error(), c)
 		return
 	}
+	if !model.Status.IsPublished() && !IsAdmin(c) {
+		res.FailWithMessage("文章不存在", c)
+		return
+	}
 	res.OkWithData(model, c)
 }
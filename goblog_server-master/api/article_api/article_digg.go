@@ -2,27 +2,66 @@ package article_api
 
 import (
 	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/middleware"
 	"gvb_server/models"
 	"gvb_server/models/res"
-	"gvb_server/service/redis_ser"
+	"gvb_server/service"
+	"gvb_server/service/digg_ser"
+	"gvb_server/service/es_ser"
 )
 
+type ArticleDiggResponse struct {
+	IsDigg    bool `json:"is_digg"`    // 操作后是否为点赞状态
+	DiggCount int  `json:"digg_count"` // 点赞数
+}
+
 // ArticleDiggView 文章点赞
 // @Tags 文章管理
 // @Summary 文章点赞
-// @Description 文章点赞
+// @Description 文章点赞，再次调用取消点赞，登录用户按用户区分，未登录按ip和ua区分
 // @Param data body models.ESIDRequest    true  "表示多个参数"
+// @Param token header string false "token"
 // @Router /api/article/digg [post]
 // @Produce json
-// @Success 200 {object} res.Response{data=string}
+// @Success 200 {object} res.Response{data=ArticleDiggResponse}
 func (ArticleApi) ArticleDiggView(c *gin.Context) {
 	var cr models.ESIDRequest
 	err := c.ShouldBindJSON(&cr)
-	if err != nil {
+	if err != nil || cr.ID == "" {
 		res.FailWithCode(res.ArgumentError, c)
 		return
 	}
-	// id长度校验及查es文章是否存在（待补充）
-	redis_ser.NewDigg().Set(cr.ID)
-	res.OkWithMessage("文章点赞成功", c)
+	article, err := es_ser.CommeDetail(cr.ID)
+	if err != nil || !article.Status.IsPublished() {
+		res.FailWithMessage("文章不存在", c)
+		return
+	}
+
+	userKey, userID := digg_ser.UserKeyByClaims(middleware.GetClaims(c), c.ClientIP(), c.GetHeader("User-Agent"))
+	isDigg, err := service.ServiceApp.DiggService.Toggle(digg_ser.DiggArticle, cr.ID, userKey, userID)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("点赞失败", c)
+		return
+	}
+
+	diggCount := article.DiggCount + 1
+	msg := "文章点赞成功"
+	if isDigg {
+		err = service.ServiceApp.NotificationService.LikeArticle(userID, article)
+		if err != nil {
+			global.Log.Error(err)
+		}
+	} else {
+		diggCount = article.DiggCount - 1
+		msg = "取消点赞成功"
+	}
+	res.Ok(ArticleDiggResponse{IsDigg: isDigg, DiggCount: diggCount}, msg, c)
+}
+
+// IsUserArticleDigg 当前用户是否点赞了文章
+func IsUserArticleDigg(c *gin.Context, articleID string) bool {
+	userKey, _ := digg_ser.UserKeyByClaims(middleware.GetClaims(c), c.ClientIP(), c.GetHeader("User-Agent"))
+	return digg_ser.IsDigg(digg_ser.DiggArticle, articleID, userKey)
 }
@@ -1,13 +1,11 @@
 package article_api
 
 import (
-	"context"
-	"encoding/json"
 	"github.com/gin-gonic/gin"
-	"github.com/olivere/elastic/v7"
 	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service/article_ser"
 )
 
 type ArticleIDTitleListResponse struct {
@@ -24,12 +22,14 @@ type ArticleIDTitleListResponse struct {
 // @Produce json
 // @Success 200 {object} res.Response{data=[]ArticleIDTitleListResponse}
 func (ArticleApi) ArticleIDTitleListView(c *gin.Context) {
-	result, err := global.ESClient.
-		Search(models.ArticleModel{}.Index()).
-		Query(elastic.NewBoolQuery()).
-		Source(`{"_source":["title"]}`).
-		Size(1000).
-		Do(context.Background())
+	option := article_ser.ListOption{
+		PageInfo: models.PageInfo{Limit: 1000},
+	}
+	// 管理员可以看到全部状态的文章
+	if IsAdmin(c) {
+		option.ShowHidden = true
+	}
+	list, _, err := article_ser.NewRepository().List(option)
 	if err != nil {
 		global.Log.Error(err)
 		res.FailWithMessage("查询失败", c)
@@ -37,12 +37,9 @@ func (ArticleApi) ArticleIDTitleListView(c *gin.Context) {
 	}
 
 	var articleIDTitleList = make([]ArticleIDTitleListResponse, 0)
-	for _, hit := range result.Hits.Hits {
-		var model models.ArticleModel
-		json.Unmarshal(hit.Source, &model)
-
+	for _, model := range list {
 		articleIDTitleList = append(articleIDTitleList, ArticleIDTitleListResponse{
-			Value: hit.Id,
+			Value: model.ID,
 			Label: model.Title,
 		})
 	}
@@ -0,0 +1,82 @@
+package article_api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/res"
+	"gvb_server/service"
+	"gvb_server/service/import_ser"
+	"gvb_server/service/redis_ser"
+	"gvb_server/utils/jwts"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ArticleImportView 导入markdown文章
+// @Tags 文章管理
+// @Summary 导入markdown文章
+// @Description 上传Hexo、Hugo的markdown文件或者zip压缩包，解析yaml头部的title、date、tags、categories、cover，返回每个文件的导入结果
+// @Param token header string true "token"
+// @Accept multipart/form-data
+// @Param files formData file true "markdown文件或zip压缩包"
+// @Router /api/articles/import [post]
+// @Produce json
+// @Success 200 {object} res.Response{data=[]import_ser.ImportResult}
+func (ArticleApi) ArticleImportView(c *gin.Context) {
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*jwts.CustomClaims)
+	form, err := c.MultipartForm()
+	if err != nil {
+		res.FailWithMessage(err.Error(), c)
+		return
+	}
+	fileList, ok := form.File["files"]
+	if !ok {
+		res.FailWithMessage("不存在的文件", c)
+		return
+	}
+
+	var avatar string
+	global.DB.Model(models.UserModel{}).Where("id = ?", claims.UserID).Select("avatar").Scan(&avatar)
+	author := import_ser.Author{
+		UserID:   claims.UserID,
+		NickName: claims.NickName,
+		Avatar:   avatar,
+	}
+
+	importService := service.ServiceApp.ImportService
+	resultList := make([]import_ser.ImportResult, 0)
+	for _, fileHeader := range fileList {
+		name := fileHeader.Filename
+		isZip := strings.ToLower(filepath.Ext(name)) == ".zip"
+		if !isZip && !import_ser.IsMarkdown(name) {
+			resultList = append(resultList, import_ser.ImportResult{File: name, Msg: "只支持markdown文件和zip压缩包"})
+			continue
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			resultList = append(resultList, import_ser.ImportResult{File: name, Msg: err.Error()})
+			continue
+		}
+		if isZip {
+			zipResultList, err := importService.ImportZip(file, fileHeader.Size, author)
+			if err != nil {
+				resultList = append(resultList, import_ser.ImportResult{File: name, Msg: err.Error()})
+			}
+			resultList = append(resultList, zipResultList...)
+			file.Close()
+			continue
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			resultList = append(resultList, import_ser.ImportResult{File: name, Msg: err.Error()})
+			continue
+		}
+		resultList = append(resultList, importService.ImportFile(name, data, author))
+	}
+	redis_ser.ClearSitemap()
+	res.OkWithData(resultList, c)
+}
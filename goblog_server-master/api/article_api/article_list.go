@@ -3,19 +3,19 @@ package article_api
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/liu-cn/json-filter/filter"
-	"github.com/olivere/elastic/v7"
 	"gvb_server/global"
+	"gvb_server/middleware"
 	"gvb_server/models"
+	"gvb_server/models/ctype"
 	"gvb_server/models/res"
 	"gvb_server/service/es_ser"
-	"gvb_server/service/redis_ser"
-	"gvb_server/utils/jwts"
 )
 
 type ArticleSearchRequest struct {
 	models.PageInfo
-	Tag    string `json:"tag" form:"tag"`
-	IsUser bool   `json:"is_user" form:"is_user"` // 根据这个参数判断是否显示我收藏的文章列表
+	Tag    string              `json:"tag" form:"tag"`
+	IsUser bool                `json:"is_user" form:"is_user"` // 根据这个参数判断是否显示我收藏的文章列表
+	Status ctype.ArticleStatus `json:"status" form:"status"`   // 按文章状态筛选，仅管理员有效
 }
 
 // ArticleListView 文章列表
@@ -33,26 +33,25 @@ func (ArticleApi) ArticleListView(c *gin.Context) {
 		res.FailWithCode(res.ArgumentError, c)
 		return
 	}
-	// 列表查询
-	boolSearch := elastic.NewBoolQuery()
+	option := es_ser.Option{
+		PageInfo: cr.PageInfo,
+		Fields:   []string{"title", "content", "category"},
+		Tag:      cr.Tag,
+	}
 
 	// 带了token
-	if cr.IsUser {
-		token := c.GetHeader("token")
-		claims, err := jwts.ParseToken(token)
+	claims := middleware.GetClaims(c)
+	if cr.IsUser && claims != nil {
+		option.UserID = claims.UserID
+	}
 
-		if err == nil && !redis_ser.CheckLogout(token) {
-			boolSearch.Must(elastic.NewTermsQuery("user_id", claims.UserID))
-		}
+	// 管理员可以看到全部状态的文章
+	if IsAdmin(c) {
+		option.ShowHidden = true
+		option.Status = cr.Status
 	}
 
-	list, count, err := es_ser.CommList(
-		es_ser.Option{
-			PageInfo: cr.PageInfo,
-			Fields:   []string{"title", "content", "category"},
-			Tag:      cr.Tag,
-			Query:    boolSearch,
-		})
+	list, count, err := es_ser.CommList(option)
 	if err != nil {
 		global.Log.Error(err.Error())
 		res.FailWithMessage("查询失败", c)
@@ -0,0 +1,32 @@
+package article_api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/service"
+	"gvb_server/service/es_ser"
+	"net/http"
+)
+
+// ArticlePageView 文章分享页
+// @Tags 文章管理
+// @Summary 文章分享页
+// @Description 服务端渲染的文章页，带og、twitter card、canonical和JSON-LD，社交平台可以解析出标题、简介和封面，之后由前端接管
+// @Param id path string true "文章id"
+// @Router /article/{id} [get]
+// @Produce html
+// @Success 200 {string} string
+func (ArticleApi) ArticlePageView(c *gin.Context) {
+	article, err := es_ser.CommeDetail(c.Param("id"))
+	if err != nil || !article.Status.IsPublished() {
+		c.String(http.StatusNotFound, "文章不存在")
+		return
+	}
+	data, err := service.ServiceApp.StaticService.ArticlePage(article)
+	if err != nil {
+		global.Log.Error(err)
+		c.String(http.StatusInternalServerError, "页面生成失败")
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", data)
+}
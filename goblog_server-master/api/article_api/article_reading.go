@@ -0,0 +1,73 @@
+package article_api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/middleware"
+	"gvb_server/models/res"
+	"gvb_server/service/es_ser"
+	"gvb_server/service/redis_ser"
+	"gvb_server/utils"
+)
+
+// 单次阅读时长的上限，页面挂着不关的不算太长
+const maxReadingSeconds = 2 * 60 * 60
+
+// 每个访客每分钟最多上报的次数
+const maxReadingPerMinute = 10
+
+type ArticleReadingRequest struct {
+	ID      string `json:"id" binding:"required" msg:"请选择文章"`
+	Seconds int    `json:"seconds" binding:"required,min=1" msg:"请输入阅读时长"` // 阅读时长，秒
+}
+
+// ArticleReadingView 上报阅读时长
+// @Tags 文章管理
+// @Summary 上报阅读时长
+// @Description 离开文章页时上报本次阅读时长，用于统计阅读时长分布，爬虫不计入，每个访客每分钟最多上报10次
+// @Param data body ArticleReadingRequest    true  "表示多个参数"
+// @Router /api/articles/reading [post]
+// @Produce json
+// @Success 200 {object} res.Response{}
+func (ArticleApi) ArticleReadingView(c *gin.Context) {
+	var cr ArticleReadingRequest
+	err := c.ShouldBindJSON(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+	userAgent := c.GetHeader("User-Agent")
+	if utils.IsBot(userAgent) {
+		res.OkWithMessage("上报成功", c)
+		return
+	}
+	var userID uint
+	if claims := middleware.GetClaims(c); claims != nil {
+		userID = claims.UserID
+	}
+	ok, err := redis_ser.AllowArticleReading(utils.VisitorKey(userID, c.ClientIP(), userAgent), maxReadingPerMinute)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("上报失败", c)
+		return
+	}
+	if !ok {
+		res.FailWithMessage("上报太频繁，请稍后再试", c)
+		return
+	}
+	article, err := es_ser.CommeDetail(cr.ID)
+	if err != nil || !article.Status.IsPublished() {
+		res.FailWithMessage("文章不存在", c)
+		return
+	}
+	if cr.Seconds > maxReadingSeconds {
+		cr.Seconds = maxReadingSeconds
+	}
+	err = redis_ser.AddArticleReading(cr.ID, cr.Seconds)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("上报失败", c)
+		return
+	}
+	res.OkWithMessage("上报成功", c)
+}
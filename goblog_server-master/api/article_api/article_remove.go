@@ -1,14 +1,13 @@
 package article_api
 
 import (
-	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/olivere/elastic/v7"
 	"gvb_server/global"
-	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service/article_ser"
 	"gvb_server/service/es_ser"
+	"gvb_server/service/redis_ser"
 )
 
 type IDListRequest struct {
@@ -36,18 +35,17 @@ func (ArticleApi) ArticleRemoveView(c *gin.Context) {
 	// 文章删除后，用户收藏过这篇文章如何处理?（代码待完善）
 	// 1.删除时把文章关联的收藏也删除
 	// 2.用户收藏表，新增一个字段，表示文章是否删除，用户可以删除这个收藏记录，但是找不到文章改收藏数
-	bulkService := global.ESClient.Bulk().Index(models.ArticleModel{}.Index()).Refresh("true")
 	for _, id := range cr.IDList {
-		req := elastic.NewBulkDeleteRequest().Id(id)
-		bulkService.Add(req)
 		// 异步全文搜索删除
 		go es_ser.DeleteFullTextByArticleID(id)
 	}
-	result, err := bulkService.Do(context.Background())
+	count, err := article_ser.NewRepository().Remove(cr.IDList)
 	if err != nil {
 		global.Log.Error(err)
 		res.FailWithMessage("删除失败", c)
 		return
 	}
-	res.OkWithMessage(fmt.Sprintf("成功删除 %d 篇文章", len(result.Succeeded())), c)
+	redis_ser.ClearSitemap()
+	redis_ser.RemoveArticleUV(cr.IDList)
+	res.OkWithMessage(fmt.Sprintf("成功删除 %d 篇文章", count), c)
 }
@@ -0,0 +1,183 @@
+package article_api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/liu-cn/json-filter/filter"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/res"
+	"gvb_server/service"
+	"gvb_server/service/common"
+	"gvb_server/service/es_ser"
+	"gvb_server/service/redis_ser"
+	"gvb_server/utils/diff"
+	"gvb_server/utils/jwts"
+	"time"
+)
+
+type ArticleRevisionListRequest struct {
+	models.PageInfo
+	ArticleID string `json:"article_id" form:"article_id" binding:"required" msg:"请选择文章"`
+}
+
+// ArticleRevisionListView 文章修订记录列表
+// @Tags 文章管理
+// @Summary 文章修订记录列表
+// @Description 文章修订记录列表，不返回正文
+// @Param data query ArticleRevisionListRequest    true  "查询参数"
+// @Param token header string true "token"
+// @Router /api/article_revisions [get]
+// @Produce json
+// @Success 200 {object} res.Response{data=res.ListResponse[models.ArticleRevisionModel]}
+func (ArticleApi) ArticleRevisionListView(c *gin.Context) {
+	var cr ArticleRevisionListRequest
+	err := c.ShouldBindQuery(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+
+	cr.Sort = "version desc"
+	list, count, _ := common.ComList(models.ArticleRevisionModel{ArticleID: cr.ArticleID}, common.Option{
+		PageInfo: cr.PageInfo,
+	})
+
+	// 判断是否为空 json-filter空值问题
+	data := filter.Omit("list", list)
+	_list, _ := data.(filter.Filter)
+	if string(_list.MustMarshalJSON()) == "{}" {
+		list = make([]models.ArticleRevisionModel, 0)
+		res.OkWithList(list, count, c)
+		return
+	}
+	res.OkWithList(data, count, c)
+}
+
+type ArticleRevisionDiffRequest struct {
+	ArticleID string `json:"article_id" form:"article_id" binding:"required" msg:"请选择文章"`
+	From      int    `json:"from" form:"from" binding:"required" msg:"请选择对比的旧版本"`
+	To        int    `json:"to" form:"to" binding:"required" msg:"请选择对比的新版本"`
+}
+
+type ArticleRevisionDiffResponse struct {
+	From    models.ArticleRevisionModel `json:"from"`
+	To      models.ArticleRevisionModel `json:"to"`
+	Title   []diff.Line                 `json:"title"`
+	Content []diff.Line                 `json:"content"`
+}
+
+// ArticleRevisionDiffView 对比两个修订版本
+// @Tags 文章管理
+// @Summary 对比两个修订版本
+// @Description 按行对比两个修订版本的标题和正文
+// @Param data query ArticleRevisionDiffRequest    true  "查询参数"
+// @Param token header string true "token"
+// @Router /api/article_revisions/diff [get]
+// @Produce json
+// @Success 200 {object} res.Response{data=ArticleRevisionDiffResponse}
+func (ArticleApi) ArticleRevisionDiffView(c *gin.Context) {
+	var cr ArticleRevisionDiffRequest
+	err := c.ShouldBindQuery(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+
+	var from, to models.ArticleRevisionModel
+	err = global.DB.Take(&from, "article_id = ? and version = ?", cr.ArticleID, cr.From).Error
+	if err != nil {
+		res.FailWithMessage(fmt.Sprintf("版本 %d 不存在", cr.From), c)
+		return
+	}
+	err = global.DB.Take(&to, "article_id = ? and version = ?", cr.ArticleID, cr.To).Error
+	if err != nil {
+		res.FailWithMessage(fmt.Sprintf("版本 %d 不存在", cr.To), c)
+		return
+	}
+
+	res.OkWithData(ArticleRevisionDiffResponse{
+		From:    from,
+		To:      to,
+		Title:   diff.Lines(from.Title, to.Title),
+		Content: diff.Lines(from.Content, to.Content),
+	}, c)
+}
+
+type ArticleRevisionRestoreRequest struct {
+	ArticleID string `json:"article_id" binding:"required" msg:"请选择文章"`
+	Version   int    `json:"version" binding:"required" msg:"请选择要恢复的版本"`
+}
+
+// ArticleRevisionRestoreView 恢复到某个修订版本
+// @Tags 文章管理
+// @Summary 恢复到某个修订版本
+// @Description 用旧版本覆盖文章内容，并生成一个新的修订版本
+// @Param data body ArticleRevisionRestoreRequest    true  "表示多个参数"
+// @Param token header string true "token"
+// @Router /api/article_revisions/restore [post]
+// @Produce json
+// @Success 200 {object} res.Response{}
+func (ArticleApi) ArticleRevisionRestoreView(c *gin.Context) {
+	var cr ArticleRevisionRestoreRequest
+	err := c.ShouldBindJSON(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*jwts.CustomClaims)
+
+	var revision models.ArticleRevisionModel
+	err = global.DB.Take(&revision, "article_id = ? and version = ?", cr.ArticleID, cr.Version).Error
+	if err != nil {
+		res.FailWithMessage("版本不存在", c)
+		return
+	}
+	_, err = es_ser.CommeDetail(cr.ArticleID)
+	if err != nil {
+		res.FailWithMessage("文章不存在", c)
+		return
+	}
+
+	var bannerUrl string
+	global.DB.Model(models.BannerModel{}).Where("id = ?", revision.BannerID).Select("path").Scan(&bannerUrl)
+
+	err = es_ser.ArticleUpdate(cr.ArticleID, map[string]any{
+		"title":      revision.Title,
+		"keyword":    revision.Title,
+		"abstract":   revision.Abstract,
+		"content":    revision.Content,
+		"category":   revision.Category,
+		"tags":       revision.Tags,
+		"banner_id":  revision.BannerID,
+		"banner_url": bannerUrl,
+		"updated_at": time.Now().Format("2006-01-02 15:04:05"),
+	})
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("恢复版本失败", c)
+		return
+	}
+
+	redis_ser.ClearSitemap()
+	newArticle, err := es_ser.CommeDetail(cr.ArticleID)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("恢复版本失败", c)
+		return
+	}
+	_, err = service.ServiceApp.ArticleService.CreateRevision(newArticle, claims.UserID, claims.NickName,
+		fmt.Sprintf("恢复至版本 %d", revision.Version))
+	if err != nil {
+		global.Log.Error(err)
+	}
+
+	// 同步全文搜索
+	if newArticle.Status.IsPublished() {
+		es_ser.DeleteFullTextByArticleID(cr.ArticleID)
+		go es_ser.AsyncArticleByFullText(cr.ArticleID, newArticle.Title, newArticle.Content)
+	}
+
+	res.OkWithMessage(fmt.Sprintf("已恢复至版本 %d", revision.Version), c)
+}
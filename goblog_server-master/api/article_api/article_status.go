@@ -0,0 +1,117 @@
+package article_api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/models/ctype"
+	"gvb_server/models/res"
+	"gvb_server/service/es_ser"
+	"gvb_server/service/redis_ser"
+	"time"
+)
+
+type ArticleStatusRequest struct {
+	ID        string              `json:"id" binding:"required" msg:"请选择文章"`
+	Status    ctype.ArticleStatus `json:"status" binding:"required" msg:"请选择文章状态"` // 1 草稿 2 待发布 3 已发布 4 已归档
+	PublishAt string              `json:"publish_at"`                              // 定时发布时间 2006-01-02 15:04:05，待发布不传时需要手动发布
+}
+
+// ArticleStatusUpdateView 修改文章状态
+// @Tags 文章管理
+// @Summary 修改文章状态
+// @Description 草稿、待发布、已发布、已归档之间的流转，发布时间在未来的文章会由定时任务发布
+// @Param data body ArticleStatusRequest    true  "文章状态"
+// @Param token header string true "token"
+// @Router /api/articles/status [put]
+// @Produce json
+// @Success 200 {object} res.Response{}
+func (ArticleApi) ArticleStatusUpdateView(c *gin.Context) {
+	var cr ArticleStatusRequest
+	err := c.ShouldBindJSON(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+	if !cr.Status.IsValid() {
+		res.FailWithMessage("文章状态错误", c)
+		return
+	}
+
+	article, err := es_ser.CommeDetail(cr.ID)
+	if err != nil {
+		res.FailWithMessage("文章不存在", c)
+		return
+	}
+	if !article.Status.CanTransitTo(cr.Status) {
+		res.FailWithMessage(fmt.Sprintf("文章不能从 %s 变为 %s", article.Status, cr.Status), c)
+		return
+	}
+
+	publishAt, err := parsePublishAt(cr.PublishAt)
+	if err != nil {
+		res.FailWithMessage("发布时间格式错误", c)
+		return
+	}
+	status, publishAtValue := schedule(cr.Status, publishAt)
+
+	data := map[string]any{
+		"status":     int(status),
+		"updated_at": time.Now().Format("2006-01-02 15:04:05"),
+	}
+	// 草稿和归档没传发布时间时保留原来的发布时间
+	if !publishAt.IsZero() || status == ctype.ArticlePublished || status == ctype.ArticleReview {
+		data["publish_at"] = publishAtData(publishAtValue)
+	}
+	err = es_ser.ArticleUpdate(cr.ID, data)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("修改文章状态失败", c)
+		return
+	}
+
+	redis_ser.ClearSitemap()
+	// 同步全文搜索，只保留已发布的文章
+	wasPublished := article.Status.IsPublished()
+	isPublished := status.IsPublished()
+	if wasPublished && !isPublished {
+		go es_ser.DeleteFullTextByArticleID(cr.ID)
+	}
+	if !wasPublished && isPublished {
+		go es_ser.AsyncArticleByFullText(cr.ID, article.Title, article.Content)
+	}
+
+	res.OkWithMessage(fmt.Sprintf("文章状态已修改为：%s", status), c)
+}
+
+// parsePublishAt 解析发布时间，不传返回零值
+func parsePublishAt(publishAt string) (time.Time, error) {
+	if publishAt == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04:05", publishAt, time.Local)
+}
+
+// publishAtData 更新用的发布时间，没有发布时间时写null，es里不会留下空字符串
+func publishAtData(publishAt string) any {
+	if publishAt == "" {
+		return nil
+	}
+	return publishAt
+}
+
+// schedule 根据发布时间确定文章状态和要保存的发布时间
+// 发布时间在未来的文章先进入待发布，到点后由定时任务发布
+// 没传发布时间时，直接发布的文章发布时间为当前时间，其他状态为空，待发布的文章需要审核后手动发布
+func schedule(status ctype.ArticleStatus, publishAt time.Time) (ctype.ArticleStatus, string) {
+	if publishAt.IsZero() {
+		if status == ctype.ArticlePublished {
+			return status, time.Now().Format("2006-01-02 15:04:05")
+		}
+		return status, ""
+	}
+	if status == ctype.ArticlePublished && publishAt.After(time.Now()) {
+		status = ctype.ArticleReview
+	}
+	return status, publishAt.Format("2006-01-02 15:04:05")
+}
@@ -1,13 +1,11 @@
 package article_api
 
 import (
-	"context"
-	"encoding/json"
 	"github.com/gin-gonic/gin"
-	"github.com/olivere/elastic/v7"
 	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service/article_ser"
 )
 
 type TagsResponse struct {
@@ -17,23 +15,6 @@ type TagsResponse struct {
 	CreatedAt     string   `json:"created_at"`
 }
 
-type TagsType struct {
-	DocCountErrorUpperBound int `json:"doc_count_error_upper_bound"`
-	SumOtherDocCount        int `json:"sum_other_doc_count"`
-	Buckets                 []struct {
-		Key      string `json:"key"`
-		DocCount int    `json:"doc_count"`
-		Articles struct {
-			DocCountErrorUpperBound int `json:"doc_count_error_upper_bound"`
-			SumOtherDocCount        int `json:"sum_other_doc_count"`
-			Buckets                 []struct {
-				Key      string `json:"key"`
-				DocCount int    `json:"doc_count"`
-			} `json:"buckets"`
-		} `json:"articles"`
-	} `json:"buckets"`
-}
-
 // ArticleTagListView 文章标签列表
 // @Tags 文章管理
 // @Summary 文章标签列表
@@ -50,53 +31,23 @@ func (ArticleApi) ArticleTagListView(c *gin.Context) {
 	if cr.Limit == 0 {
 		cr.Limit = 50
 	}
-	offset := (cr.Page - 1) * cr.Limit
-	if offset < 0 {
-		offset = 0
-	}
 
-	result, err := global.ESClient.
-		Search(models.ArticleModel{}.Index()).
-		Aggregation("tags", elastic.NewCardinalityAggregation().Field("tags")).
-		Size(cr.Limit).
-		Do(context.Background())
-	cTag, _ := result.Aggregations.Cardinality("tags")
-	count := int64(*cTag.Value)
-	agg := elastic.NewTermsAggregation().Field("tags")
-
-	agg.SubAggregation("articles", elastic.NewTermsAggregation().Field("keyword"))
-	agg.SubAggregation("page", elastic.NewBucketSortAggregation().From(offset).Size(cr.Limit))
-	query := elastic.NewBoolQuery()
-
-	result, err = global.ESClient.
-		Search(models.ArticleModel{}.Index()).
-		Query(query).
-		Aggregation("tags", agg).
-		Size(cr.Limit).
-		Do(context.Background())
+	tagCountList, count, err := article_ser.NewRepository().TagList(cr.Page, cr.Limit)
 	if err != nil {
 		global.Log.Error(err)
 		res.FailWithMessage(err.Error(), c)
 		return
 	}
 
-	var tagType TagsType
 	var tagList = make([]*TagsResponse, 0)
-	_ = json.Unmarshal(result.Aggregations["tags"], &tagType)
 	var tagStringList []string
-	for _, bucket := range tagType.Buckets {
-
-		var articleList []string
-		for _, s := range bucket.Articles.Buckets {
-			articleList = append(articleList, s.Key)
-		}
-		//fmt.Println(bucket.Key)
+	for _, tagCount := range tagCountList {
 		tagList = append(tagList, &TagsResponse{
-			Tag:           bucket.Key,
-			Count:         bucket.DocCount,
-			ArticleIDList: articleList,
+			Tag:           tagCount.Tag,
+			Count:         tagCount.Count,
+			ArticleIDList: tagCount.TitleList,
 		})
-		tagStringList = append(tagStringList, bucket.Key)
+		tagStringList = append(tagStringList, tagCount.Tag)
 	}
 
 	var tagModelList []models.TagModel
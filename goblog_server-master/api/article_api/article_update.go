@@ -7,7 +7,11 @@ import (
 	"gvb_server/models"
 	"gvb_server/models/ctype"
 	"gvb_server/models/res"
+	"gvb_server/service"
+	"gvb_server/service/article_ser"
 	"gvb_server/service/es_ser"
+	"gvb_server/service/redis_ser"
+	"gvb_server/utils/jwts"
 	"time"
 )
 
@@ -21,6 +25,7 @@ type ArticleUpdateRequest struct {
 	BannerID uint        `json:"banner_id"` // 文章封面ID
 	Tags     ctype.Array `json:"tags"`      // 文章标签
 	ID       string      `json:"id"`
+	Summary  string      `json:"summary"` // 修改说明，不传根据修改的字段生成
 }
 
 // ArticleUpdateView 更新文章
@@ -93,12 +98,19 @@ func (ArticleApi) ArticleUpdateView(c *gin.Context) {
 		DataMap[key] = v
 	}
 
-	err = article.GetDataByID(cr.ID)
+	article, err = article_ser.NewRepository().Detail(cr.ID)
 	if err != nil {
 		res.FailWithMessage("文章不存在", c)
 		return
 	}
 
+	// 老文章没有修订记录，先保存修改前的版本
+	articleService := service.ServiceApp.ArticleService
+	err = articleService.EnsureInitRevision(article)
+	if err != nil {
+		global.Log.Error(err)
+	}
+
 	//fmt.Println(DataMap)
 	err = es_ser.ArticleUpdate(cr.ID, DataMap)
 	if err != nil {
@@ -108,10 +120,27 @@ func (ArticleApi) ArticleUpdateView(c *gin.Context) {
 	}
 
 	// 更新成功，同步数据到全文搜索
+	// 未发布的文章不在全文搜索中，等发布时再同步
 	newArticle, err := es_ser.CommeDetail(cr.ID)
-	if article.Content != newArticle.Content || article.Title != newArticle.Title {
+	if err == nil && newArticle.Status.IsPublished() &&
+		(article.Content != newArticle.Content || article.Title != newArticle.Title) {
 		es_ser.DeleteFullTextByArticleID(cr.ID)
-		es_ser.AsyncArticleByFullText(cr.ID, article.Title, newArticle.Content)
+		es_ser.AsyncArticleByFullText(cr.ID, newArticle.Title, newArticle.Content)
+	}
+
+	// 保存修订版本
+	if err == nil {
+		redis_ser.ClearSitemap()
+		_claims, _ := c.Get("claims")
+		claims := _claims.(*jwts.CustomClaims)
+		summary := cr.Summary
+		if summary == "" {
+			summary = articleService.ChangeSummary(article, newArticle)
+		}
+		_, err = articleService.CreateRevision(newArticle, claims.UserID, claims.NickName, summary)
+		if err != nil {
+			global.Log.Error(err)
+		}
 	}
 
 	res.OkWithMessage("更新成功", c)
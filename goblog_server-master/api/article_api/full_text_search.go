@@ -9,6 +9,7 @@ import (
 	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service/article_ser"
 )
 
 // FullTextContextView 全文搜索
@@ -23,6 +24,12 @@ func (ArticleApi) FullTextContextView(c *gin.Context) {
 	var cr models.PageInfo
 	_ = c.ShouldBindQuery(&cr)
 
+	// 文章存在mysql时没有全文搜索索引，直接搜文章
+	if !article_ser.IsES() {
+		fullTextByArticle(cr, c)
+		return
+	}
+
 	boolQuery := elastic.NewBoolQuery()
 	if cr.Key != "" {
 		boolQuery.Must(elastic.NewMultiMatchQuery(cr.Key, "title", "body"))
@@ -63,3 +70,27 @@ func (ArticleApi) FullTextContextView(c *gin.Context) {
 	res.OkWithList(fullTextList, count, c)
 
 }
+
+// fullTextByArticle 用文章列表模拟全文搜索的结果
+func fullTextByArticle(cr models.PageInfo, c *gin.Context) {
+	list, count, err := article_ser.NewRepository().List(article_ser.ListOption{
+		PageInfo: cr,
+		Fields:   []string{"title", "content"},
+	})
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("查询失败", c)
+		return
+	}
+	fullTextList := make([]models.FullTextModel, 0)
+	for _, article := range list {
+		fullTextList = append(fullTextList, models.FullTextModel{
+			ID:    article.ID,
+			Key:   article.ID,
+			Title: article.Title,
+			Slug:  article.ID,
+			Body:  article.Abstract,
+		})
+	}
+	res.OkWithList(fullTextList, int64(count), c)
+}
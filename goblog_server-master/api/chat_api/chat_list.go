@@ -3,31 +3,39 @@ package chat_api
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/liu-cn/json-filter/filter"
+	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/res"
-	"gvb_server/service/common"
+	"gvb_server/service/chat_ser"
 )
 
+type ChatListRequest struct {
+	models.PageInfo
+	RoomID uint `form:"room_id"` // 聊天室id，不传查大厅
+}
+
 // ChatListView 群聊记录
 // @Tags 群聊管理
 // @Summary 群聊记录
-// @Description 群聊记录
-// @Param data query models.PageInfo    false  "查询参数"
+// @Description 群聊记录，按聊天室分页
+// @Param data query ChatListRequest    false  "查询参数"
 // @Router /api/chat_groups_records [get]
 // @Produce json
 // @Success 200 {object} res.Response{data=res.ListResponse[models.ChatModel]}
 func (ChatApi) ChatListView(c *gin.Context) {
-	var cr models.PageInfo
+	var cr ChatListRequest
 	err := c.ShouldBindQuery(&cr)
 	if err != nil {
 		res.FailWithCode(res.ArgumentError, c)
 		return
 	}
 
-	cr.Sort = "created_at desc"
-	list, count, _ := common.ComList(models.ChatModel{ISGroup: true}, common.Option{
-		PageInfo: cr,
-	})
+	list, count, err := chat_ser.RecordList(cr.RoomID, cr.PageInfo)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("查询失败", c)
+		return
+	}
 
 	// 判断是否为空 json-filter空值问题
 	data := filter.Omit("list", list)
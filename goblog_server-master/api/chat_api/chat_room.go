@@ -0,0 +1,96 @@
+package chat_api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/res"
+	"gvb_server/service/chat_ser"
+	"gvb_server/utils/jwts"
+)
+
+type ChatRoomRequest struct {
+	Name     string `json:"name" binding:"required,max=32" msg:"请输入聊天室名称，最多32个字"`
+	Abstract string `json:"abstract" binding:"max=128" msg:"简介最多128个字"`
+}
+
+// ChatRoomCreateView 创建聊天室
+// @Tags 群聊管理
+// @Summary 创建聊天室
+// @Description 创建聊天室
+// @Param data body ChatRoomRequest    true  "表示多个参数"
+// @Param token header string true "token"
+// @Router /api/chat_rooms [post]
+// @Produce json
+// @Success 200 {object} res.Response{data=models.ChatRoomModel}
+func (ChatApi) ChatRoomCreateView(c *gin.Context) {
+	var cr ChatRoomRequest
+	err := c.ShouldBindJSON(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*jwts.CustomClaims)
+
+	room := models.ChatRoomModel{
+		Name:     cr.Name,
+		Abstract: cr.Abstract,
+		UserID:   claims.UserID,
+	}
+	err = chat_ser.CreateRoom(&room)
+	if errors.Is(err, chat_ser.ErrRoomExists) {
+		res.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("创建聊天室失败", c)
+		return
+	}
+	res.Ok(room, "创建聊天室成功", c)
+}
+
+// ChatRoomListView 聊天室列表
+// @Tags 群聊管理
+// @Summary 聊天室列表
+// @Description 聊天室列表和在线人数，id为0的是大厅
+// @Router /api/chat_rooms [get]
+// @Produce json
+// @Success 200 {object} res.Response{data=[]chat_ser.RoomResponse}
+func (ChatApi) ChatRoomListView(c *gin.Context) {
+	list, err := chat_ser.RoomList()
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("查询失败", c)
+		return
+	}
+	res.OkWithData(list, c)
+}
+
+// ChatRoomRemoveView 删除聊天室
+// @Tags 群聊管理
+// @Summary 删除聊天室
+// @Description 删除聊天室和里面的聊天记录
+// @Param data body models.RemoveRequest    true  "表示多个参数"
+// @Param token header string true "token"
+// @Router /api/chat_rooms [delete]
+// @Produce json
+// @Success 200 {object} res.Response{}
+func (ChatApi) ChatRoomRemoveView(c *gin.Context) {
+	var cr models.RemoveRequest
+	err := c.ShouldBindJSON(&cr)
+	if err != nil || len(cr.IDList) == 0 {
+		res.FailWithCode(res.ArgumentError, c)
+		return
+	}
+	count, err := chat_ser.RemoveRooms(cr.IDList)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("删除聊天室失败", c)
+		return
+	}
+	res.OkWithMessage(fmt.Sprintf("共删除 %d 个聊天室", count), c)
+}
@@ -1,13 +1,15 @@
 package comment_api
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 	"gvb_server/global"
 	"gvb_server/models"
+	"gvb_server/models/ctype"
 	"gvb_server/models/res"
+	"gvb_server/service"
+	"gvb_server/service/comment_ser"
 	"gvb_server/service/es_ser"
-	"gvb_server/service/redis_ser"
 	"gvb_server/utils/jwts"
 )
 
@@ -37,20 +39,38 @@ func (CommentApi) CommentCreateView(c *gin.Context) {
 	_claims, _ := c.Get("claims")
 	claims := _claims.(*jwts.CustomClaims)
 
+	var user models.UserModel
+	err = global.DB.Take(&user, claims.UserID).Error
+	if err != nil {
+		res.FailWithMessage("用户不存在", c)
+		return
+	}
+	ok, err := service.ServiceApp.CommentService.AllowComment(user)
+	if err != nil {
+		global.Log.Error(err)
+	}
+	if err == nil && !ok {
+		res.FailWithMessage("评论太频繁，请稍后再试", c)
+		return
+	}
+
 	// 文章是否存在
-	_, err = es_ser.CommeDetail(cr.ArticleID)
+	article, err := es_ser.CommeDetail(cr.ArticleID)
 	if err != nil {
 		res.FailWithMessage("文章不存在", c)
 		return
 	}
+	if !article.Status.IsPublished() {
+		res.FailWithMessage("文章未发布，不能评论", c)
+		return
+	}
 
 	// 判断是否是子评论
+	var parentComment *models.CommentModel
 	if cr.ParentCommentID != nil {
-		// 子评论
-		// 父评论id
-		var parentComment models.CommentModel
 		// 找父评论
-		err = global.DB.Take(&parentComment, cr.ParentCommentID).Error
+		parentComment = new(models.CommentModel)
+		err = global.DB.Take(parentComment, cr.ParentCommentID).Error
 		if err != nil {
 			res.FailWithMessage("父评论不存在", c)
 			return
@@ -60,20 +80,48 @@ func (CommentApi) CommentCreateView(c *gin.Context) {
 			res.FailWithMessage("评论文章不一致", c)
 			return
 		}
-		// 给父评论数 + 1
-		global.DB.Model(&parentComment).Update("comment_count", gorm.Expr("comment_count + 1"))
+		// 没通过审核的评论不能回复
+		if parentComment.Status != ctype.CommentApproved {
+			res.FailWithMessage("父评论不存在", c)
+			return
+		}
+		if parentComment.IsDeleted {
+			res.FailWithMessage("父评论已删除，不能回复", c)
+			return
+		}
+	}
+	// 添加评论，通过审核的子评论会给父评论数 + 1
+	verdict := service.ServiceApp.CommentService.Moderate(user, cr.Content)
+	comment := models.CommentModel{
+		Content:   cr.Content,
+		ArticleID: cr.ArticleID,
+		UserID:    claims.UserID,
+		Status:    verdict.Status,
+		Reason:    verdict.Reason,
+	}
+	err = service.ServiceApp.CommentService.Create(&comment, parentComment)
+	if errors.Is(err, comment_ser.ErrTooDeep) {
+		res.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("评论失败", c)
+		return
+	}
+	if verdict.Status != ctype.CommentApproved {
+		res.OkWithMessage("评论已提交，等待审核", c)
+		return
 	}
-	// 添加评论
-	global.DB.Create(&models.CommentModel{
-		ParentCommentID: cr.ParentCommentID,
-		Content:         cr.Content,
-		ArticleID:       cr.ArticleID,
-		UserID:          claims.UserID,
-	})
 	// 拿到文章数，新的文章评论数存缓存离
 	//newCommentCount := article.CommentCount + 1
 	// 文章评论数+1
-	redis_ser.NewCommentCount().Set(cr.ArticleID)
+	service.ServiceApp.CommentService.AddArticleCount(cr.ArticleID, 1)
+	// 通知被回复和被@的人
+	err = service.ServiceApp.NotificationService.Comment(comment)
+	if err != nil {
+		global.Log.Error(err)
+	}
 	res.OkWithMessage("评论已发送", c)
 	return
 
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"gvb_server/global"
+	"gvb_server/middleware"
 	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service"
+	"gvb_server/service/digg_ser"
 	"gvb_server/service/redis_ser"
 )
 
@@ -13,15 +16,21 @@ type CommentIDRequest struct {
 	ID uint `json:"id" form:"id" uri:"id"`
 }
 
+type CommentDiggResponse struct {
+	IsDigg    bool `json:"is_digg"`    // 操作后是否为点赞状态
+	DiggCount int  `json:"digg_count"` // 点赞数
+}
+
 // CommentDigg 评论点赞
 // @Tags 评论管理
 // @Summary 评论点赞
-// @Description 评论点赞
+// @Description 评论点赞，再次调用取消点赞，登录用户按用户区分，未登录按ip和ua区分
 // @Param data body CommentIDRequest    true  "表示多个参数"
 // @Param id path int true "文章id"
+// @Param token header string false "token"
 // @Router /api/comments/{id} [post]
 // @Produce json
-// @Success 200 {object} res.Response{}
+// @Success 200 {object} res.Response{data=CommentDiggResponse}
 func (CommentApi) CommentDigg(c *gin.Context) {
 	var cr CommentIDRequest
 	err := c.ShouldBindUri(&cr)
@@ -38,6 +47,26 @@ func (CommentApi) CommentDigg(c *gin.Context) {
 		return
 	}
 
-	redis_ser.NewCommentDigg().Set(fmt.Sprintf("%d", cr.ID))
-	res.OkWithMessage("评论点赞成功", c)
+	id := fmt.Sprintf("%d", cr.ID)
+	userKey, userID := digg_ser.UserKeyByClaims(middleware.GetClaims(c), c.ClientIP(), c.GetHeader("User-Agent"))
+	isDigg, err := service.ServiceApp.DiggService.Toggle(digg_ser.DiggComment, id, userKey, userID)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("点赞失败", c)
+		return
+	}
+
+	if isDigg {
+		err = service.ServiceApp.NotificationService.LikeComment(userID, commentModel)
+		if err != nil {
+			global.Log.Error(err)
+		}
+	}
+
+	diggCount := commentModel.DiggCount + redis_ser.NewCommentDigg().Get(id)
+	msg := "评论点赞成功"
+	if !isDigg {
+		msg = "取消点赞成功"
+	}
+	res.Ok(CommentDiggResponse{IsDigg: isDigg, DiggCount: diggCount}, msg, c)
 }
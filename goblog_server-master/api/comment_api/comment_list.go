@@ -5,20 +5,25 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/liu-cn/json-filter/filter"
 	"gvb_server/global"
+	"gvb_server/middleware"
 	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service"
+	"gvb_server/service/comment_ser"
+	"gvb_server/service/digg_ser"
 	"gvb_server/service/redis_ser"
 )
 
 type CommentListRequest struct {
-	ArticleID string `form:"article_id"`
+	models.PageInfo
+	ReplyLimit *int `form:"reply_limit"` // 每条根评论带的回复数，默认3
 }
 
 // CommentListView 文章下的评论列表
 // @Tags 评论管理
 // @Summary 文章下的评论列表
-// @Description 文章下的评论列表
-// @Param data query models.PageInfo    false  "查询参数"
+// @Description 文章下的根评论分页，每条根评论带前几条回复组成的树，reply_cursor不为空时可以加载更多回复
+// @Param data query CommentListRequest    false  "查询参数"
 // @Param id path string true "文章id"
 // @Router /api/comments/{id} [get]
 // @Produce json
@@ -30,55 +35,81 @@ func (CommentApi) CommentListView(c *gin.Context) {
 		res.FailWithError(err, &cr, c)
 		return
 	}
-	cr.ArticleID = c.Param("id")
+	if cr.Limit <= 0 || cr.Limit > 50 {
+		cr.Limit = 10
+	}
+	replyLimit := 3
+	if cr.ReplyLimit != nil && *cr.ReplyLimit >= 0 && *cr.ReplyLimit <= 50 {
+		replyLimit = *cr.ReplyLimit
+	}
 
-	rootCommentList := FindArticleCommentList(cr.ArticleID)
-	res.OkWithData(filter.Select("c", rootCommentList), c)
-	return
+	list, count, err := service.ServiceApp.CommentService.RootList(c.Param("id"), cr.PageInfo, replyLimit)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("查询失败", c)
+		return
+	}
+	setCommentDigg(c, list)
+	res.OkWithList(filter.Select("c", list), count, c)
 }
 
-func FindArticleCommentList(articleID string) (RootCommentList []*models.CommentModel) {
-	// 先把文章下的根评论查出
-	global.DB.Preload("User").Find(&RootCommentList, "article_id = ? and parent_comment_id is null", articleID)
-	// 遍历根评论，递归查根评论下的所有子评论
-	diggInfo := redis_ser.NewCommentDigg().GetInfo()
-	for _, model := range RootCommentList {
-		var subCommentList, newSubCommentList []models.CommentModel
-		FindSubComment(*model, &subCommentList)
-		for _, commentModel := range subCommentList {
-			digg := diggInfo[fmt.Sprintf("%d", commentModel.ID)]
-			commentModel.DiggCount = commentModel.DiggCount + digg
-			newSubCommentList = append(newSubCommentList, commentModel)
-		}
-		modelDigg := diggInfo[fmt.Sprintf("%d", model.ID)]
-		model.DiggCount = model.DiggCount + modelDigg
-		model.SubComments = newSubCommentList
-	}
-	return
+type CommentReplyListRequest struct {
+	RootID uint   `form:"root_id" binding:"required" msg:"请选择评论"`
+	Cursor string `form:"cursor" binding:"required" msg:"缺少游标"` // 上一次返回的reply_cursor
+	Limit  int    `form:"limit"`                                // 默认10条
 }
 
-// FindSubComment 递归查某评论下的子评论
-func FindSubComment(model models.CommentModel, subCommentList *[]models.CommentModel) {
-	global.DB.Preload("SubComments.User").Take(&model)
-	for _, sub := range model.SubComments {
-		*subCommentList = append(*subCommentList, sub)
-		FindSubComment(sub, subCommentList)
-	}
-	return
+type CommentReplyListResponse struct {
+	List   any    `json:"list"`
+	Cursor string `json:"cursor"` // 为空时没有更多回复
 }
 
-// FindSubCommentCount 查子评论个数
-func FindSubCommentCount(model models.CommentModel) (subCommentList []models.CommentModel) {
-	findSubCommentList(model, &subCommentList)
-	return
+// CommentReplyListView 加载更多回复
+// @Tags 评论管理
+// @Summary 加载更多回复
+// @Description 按游标加载某条根评论下的回复，父评论已经加载过的回复作为顶层返回，按display_parent_id挂到对应的评论下，父评论没有通过审核时为根评论
+// @Param data query CommentReplyListRequest    true  "查询参数"
+// @Router /api/comments/replies [get]
+// @Produce json
+// @Success 200 {object} res.Response{data=CommentReplyListResponse}
+func (CommentApi) CommentReplyListView(c *gin.Context) {
+	var cr CommentReplyListRequest
+	err := c.ShouldBindQuery(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+	if cr.Limit <= 0 || cr.Limit > 50 {
+		cr.Limit = 10
+	}
+	list, cursor, err := service.ServiceApp.CommentService.ReplyList(cr.RootID, cr.Cursor, cr.Limit)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("查询失败", c)
+		return
+	}
+	setCommentDigg(c, list)
+	res.OkWithData(CommentReplyListResponse{
+		List:   filter.Select("c", list),
+		Cursor: cursor,
+	}, c)
 }
 
-// 查子评论列表
-func findSubCommentList(model models.CommentModel, subCommentList *[]models.CommentModel) {
-	global.DB.Preload("SubComments").Take(&model)
-	for _, sub := range model.SubComments {
-		*subCommentList = append(*subCommentList, sub)
-		FindSubComment(sub, subCommentList)
+// setCommentDigg 加上缓存里还没同步的点赞数，标记当前用户点过赞的评论
+func setCommentDigg(c *gin.Context, list []*models.CommentModel) {
+	var idList []string
+	comment_ser.Walk(list, func(model *models.CommentModel) {
+		idList = append(idList, fmt.Sprintf("%d", model.ID))
+	})
+	if len(idList) == 0 {
+		return
 	}
-	return
+	diggInfo := redis_ser.NewCommentDigg().GetInfo()
+	userKey, _ := digg_ser.UserKeyByClaims(middleware.GetClaims(c), c.ClientIP(), c.GetHeader("User-Agent"))
+	diggMap := digg_ser.DiggMap(digg_ser.DiggComment, userKey, idList)
+	comment_ser.Walk(list, func(model *models.CommentModel) {
+		id := fmt.Sprintf("%d", model.ID)
+		model.DiggCount = model.DiggCount + diggInfo[id]
+		model.IsDigg = diggMap[id]
+	})
 }
@@ -1,13 +1,11 @@
 package comment_api
 
 import (
-	"context"
-	"encoding/json"
 	"github.com/gin-gonic/gin"
-	"github.com/olivere/elastic/v7"
-	"gvb_server/global"
 	"gvb_server/models"
+	"gvb_server/models/ctype"
 	"gvb_server/models/res"
+	"gvb_server/service/article_ser"
 	"gvb_server/service/common"
 	"time"
 )
@@ -36,7 +34,8 @@ func (CommentApi) CommentListAllView(c *gin.Context) {
 	var cr models.PageInfo
 	c.ShouldBindQuery(&cr)
 
-	list, count, _ := common.ComList(models.CommentModel{}, common.Option{
+	// 只显示通过审核的评论
+	list, count, _ := common.ComList(models.CommentModel{Status: ctype.CommentApproved}, common.Option{
 		PageInfo: cr,
 		Preload:  []string{"User"},
 	})
@@ -44,33 +43,21 @@ func (CommentApi) CommentListAllView(c *gin.Context) {
 	var commentList = make([]CommentListResponse, 0)
 
 	var collMap = map[string]models.ArticleModel{}
-	var articleIDList []interface{}
+	var articleIDList []string
 	for _, model := range list {
 		articleIDList = append(articleIDList, model.ArticleID)
 		collMap[model.ArticleID] = models.ArticleModel{}
 	}
 
-	boolSearch := elastic.NewTermsQuery("_id", articleIDList...)
-
-	// 传id列表，查询es
-	result, err := global.ESClient.
-		Search(models.ArticleModel{}.Index()).
-		Query(boolSearch).
-		Size(1000).
-		Do(context.Background())
+	// 传id列表，查询文章
+	articleList, err := article_ser.NewRepository().ListByIDList(articleIDList)
 	if err != nil {
 		res.FailWithMessage(err.Error(), c)
 		return
 	}
 
-	for _, hit := range result.Hits.Hits {
-		var article models.ArticleModel
-		err = json.Unmarshal(hit.Source, &article)
-		if err != nil {
-			global.Log.Error(err)
-			continue
-		}
-		collMap[hit.Id] = article
+	for _, article := range articleList {
+		collMap[article.ID] = article
 	}
 
 	for _, model := range list {
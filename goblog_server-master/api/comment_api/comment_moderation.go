@@ -0,0 +1,107 @@
+package comment_api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/models/res"
+	"gvb_server/service"
+	"gvb_server/service/common"
+	"time"
+)
+
+type CommentModerationRequest struct {
+	models.PageInfo
+	Status ctype.CommentStatus `form:"status"` // 不传默认待审核
+}
+
+type CommentModerationResponse struct {
+	ID              uint                `json:"id"`
+	CreatedAt       time.Time           `json:"created_at"`
+	ArticleID       string              `json:"article_id"`
+	ParentCommentID *uint               `json:"parent_comment_id"`
+	Content         string              `json:"content"`
+	Status          ctype.CommentStatus `json:"status"`
+	Reason          string              `json:"reason"`
+	UserID          uint                `json:"user_id"`
+	UserNickName    string              `json:"user_nick_name"`
+	TrustLevel      ctype.TrustLevel    `json:"trust_level"`
+}
+
+// CommentModerationListView 审核评论列表
+// @Tags 评论管理
+// @Summary 审核评论列表
+// @Description 审核评论列表，status 1 待审核 2 已通过 3 已拒绝 4 垃圾评论
+// @Param data query CommentModerationRequest    false  "查询参数"
+// @Param token header string true "token"
+// @Router /api/comments/moderation [get]
+// @Produce json
+// @Success 200 {object} res.Response{data=res.ListResponse[CommentModerationResponse]}
+func (CommentApi) CommentModerationListView(c *gin.Context) {
+	var cr CommentModerationRequest
+	err := c.ShouldBindQuery(&cr)
+	if err != nil {
+		res.FailWithCode(res.ArgumentError, c)
+		return
+	}
+	if cr.Status == 0 {
+		cr.Status = ctype.CommentPending
+	}
+	if !cr.Status.IsValid() {
+		res.FailWithMessage("审核状态错误", c)
+		return
+	}
+
+	list, count, _ := common.ComList(models.CommentModel{Status: cr.Status}, common.Option{
+		PageInfo: cr.PageInfo,
+		Preload:  []string{"User"},
+	})
+	var commentList = make([]CommentModerationResponse, 0)
+	for _, model := range list {
+		commentList = append(commentList, CommentModerationResponse{
+			ID:              model.ID,
+			CreatedAt:       model.CreatedAt,
+			ArticleID:       model.ArticleID,
+			ParentCommentID: model.ParentCommentID,
+			Content:         model.Content,
+			Status:          model.Status,
+			Reason:          model.Reason,
+			UserID:          model.UserID,
+			UserNickName:    model.User.NickName,
+			TrustLevel:      model.User.TrustLevel,
+		})
+	}
+	res.OkWithList(commentList, count, c)
+}
+
+type CommentModerationUpdateRequest struct {
+	IDList []uint              `json:"id_list" binding:"required" msg:"请选择评论"`
+	Status ctype.CommentStatus `json:"status" binding:"required,oneof=2 3 4" msg:"审核状态错误"`
+}
+
+// CommentModerationUpdateView 批量审核评论
+// @Tags 评论管理
+// @Summary 批量审核评论
+// @Description 批量审核评论，status 2 通过 3 拒绝 4 垃圾评论
+// @Param data body CommentModerationUpdateRequest    true  "表示多个参数"
+// @Param token header string true "token"
+// @Router /api/comments/moderation [put]
+// @Produce json
+// @Success 200 {object} res.Response{}
+func (CommentApi) CommentModerationUpdateView(c *gin.Context) {
+	var cr CommentModerationUpdateRequest
+	err := c.ShouldBindJSON(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+	count, err := service.ServiceApp.CommentService.SetStatus(cr.IDList, cr.Status)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("审核失败", c)
+		return
+	}
+	res.OkWithMessage(fmt.Sprintf("共审核 %d 条评论", count), c)
+}
@@ -1,21 +1,20 @@
 package comment_api
 
 import (
-	"fmt"
+	"errors"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/res"
-	"gvb_server/service/redis_ser"
-	"gvb_server/utils"
+	"gvb_server/service"
+	"gvb_server/service/comment_ser"
 	"gvb_server/utils/jwts"
 )
 
-// CommentRemoveView 批量删除评论
+// CommentRemoveView 删除评论
 // @Tags 评论管理
-// @Summary 批量删除评论
-// @Description 批量删除评论
+// @Summary 删除评论
+// @Description 删除评论，内容替换为[deleted]，回复保留，管理员可以恢复
 // @Param token header string true "token"
 // @Param id path string true "评论id"
 // @Router /api/comments/{id} [delete]
@@ -46,31 +45,17 @@ func (CommentApi) CommentRemoveView(c *gin.Context) {
 		return
 	}
 
-	// 统计评论下的子评论数 自身也算进去
-	subCommentList := FindSubCommentCount(commentModel)
-	count := len(subCommentList) + 1
-	redis_ser.NewCommentCount().SetCount(commentModel.ArticleID, -count)
-
-	// 判断是否是子评论
-	if commentModel.ParentCommentID != nil {
-		// 子评论
-		// 找父评论，减掉对应的评论数
-		global.DB.Model(&models.CommentModel{}).
-			Where("id = ?", *commentModel.ParentCommentID).
-			Update("comment_count", gorm.Expr("comment_count - ?", count))
-	}
-	// 删除子评论以及当前评论
-	var deleteCommentIDList []uint
-	for _, model := range subCommentList {
-		deleteCommentIDList = append(deleteCommentIDList, model.ID)
+	// 通过审核的评论删除后，父评论和文章的评论数-1
+	err = service.ServiceApp.CommentService.Remove(&commentModel, claims.UserID)
+	if errors.Is(err, comment_ser.ErrDeleted) {
+		res.FailWithMessage(err.Error(), c)
+		return
 	}
-	// 切片反转，然后逐个删
-	utils.Reverse(deleteCommentIDList)
-	deleteCommentIDList = append(deleteCommentIDList, commentModel.ID)
-	for _, id := range deleteCommentIDList {
-		global.DB.Model(models.CommentModel{}).Delete("id = ?", id)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("删除失败", c)
+		return
 	}
-
-	res.OkWithMessage(fmt.Sprintf("共删除 %d 条评论", len(deleteCommentIDList)), c)
+	res.OkWithMessage("评论已删除", c)
 	return
 }
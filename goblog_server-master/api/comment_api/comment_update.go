@@ -0,0 +1,162 @@
+package comment_api
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/models/res"
+	"gvb_server/service"
+	"gvb_server/service/comment_ser"
+	"gvb_server/utils/jwts"
+)
+
+type CommentUpdateRequest struct {
+	Content string `json:"content" binding:"required" msg:"请输入评论内容"`
+}
+
+// CommentUpdateView 编辑评论
+// @Tags 评论管理
+// @Summary 编辑评论
+// @Description 编辑自己的评论，只能在发表后的一段时间内编辑，之前的内容保存在历史里
+// @Param token header string true "token"
+// @Param id path string true "评论id"
+// @Param data body CommentUpdateRequest    true  "表示多个参数"
+// @Router /api/comments/{id} [put]
+// @Produce json
+// @Success 200 {object} res.Response{}
+func (CommentApi) CommentUpdateView(c *gin.Context) {
+	var id CommentIDRequest
+	err := c.ShouldBindUri(&id)
+	if err != nil {
+		res.FailWithCode(res.ArgumentError, c)
+		return
+	}
+	var cr CommentUpdateRequest
+	err = c.ShouldBindJSON(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*jwts.CustomClaims)
+
+	var commentModel models.CommentModel
+	err = global.DB.Take(&commentModel, id.ID).Error
+	if err != nil {
+		res.FailWithMessage("评论不存在", c)
+		return
+	}
+	if commentModel.UserID != claims.UserID {
+		res.FailWithMessage("只能编辑自己的评论", c)
+		return
+	}
+	if !service.ServiceApp.CommentService.CanEdit(commentModel) {
+		res.FailWithMessage("已超过可编辑的时间", c)
+		return
+	}
+	if commentModel.Content == cr.Content {
+		res.OkWithMessage("评论内容没有变化", c)
+		return
+	}
+
+	var user models.UserModel
+	err = global.DB.Take(&user, claims.UserID).Error
+	if err != nil {
+		res.FailWithMessage("用户不存在", c)
+		return
+	}
+	// 修改后的内容重新审核
+	verdict := service.ServiceApp.CommentService.Moderate(user, cr.Content)
+	err = service.ServiceApp.CommentService.Edit(&commentModel, claims.UserID, cr.Content, verdict)
+	if errors.Is(err, comment_ser.ErrDeleted) {
+		res.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("编辑失败", c)
+		return
+	}
+	if verdict.Status != ctype.CommentApproved {
+		res.OkWithMessage("评论已提交，等待审核", c)
+		return
+	}
+	res.OkWithMessage("评论已修改", c)
+}
+
+// CommentRestoreView 恢复删除的评论
+// @Tags 评论管理
+// @Summary 恢复删除的评论
+// @Description 恢复删除的评论，内容恢复为删除前的内容
+// @Param token header string true "token"
+// @Param id path string true "评论id"
+// @Router /api/comments/restore/{id} [post]
+// @Produce json
+// @Success 200 {object} res.Response{}
+func (CommentApi) CommentRestoreView(c *gin.Context) {
+	var cr CommentIDRequest
+	err := c.ShouldBindUri(&cr)
+	if err != nil {
+		res.FailWithCode(res.ArgumentError, c)
+		return
+	}
+	var commentModel models.CommentModel
+	err = global.DB.Take(&commentModel, cr.ID).Error
+	if err != nil {
+		res.FailWithMessage("评论不存在", c)
+		return
+	}
+	err = service.ServiceApp.CommentService.Restore(&commentModel)
+	if errors.Is(err, comment_ser.ErrNotDeleted) {
+		res.FailWithMessage(err.Error(), c)
+		return
+	}
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("恢复失败", c)
+		return
+	}
+	res.OkWithMessage("评论已恢复", c)
+}
+
+// CommentHistoryListView 评论的历史版本
+// @Tags 评论管理
+// @Summary 评论的历史版本
+// @Description 评论编辑和删除前的内容，新的在前，评论作者和管理员可以查看
+// @Param token header string true "token"
+// @Param id path string true "评论id"
+// @Router /api/comments/history/{id} [get]
+// @Produce json
+// @Success 200 {object} res.Response{data=[]models.CommentHistoryModel}
+func (CommentApi) CommentHistoryListView(c *gin.Context) {
+	var cr CommentIDRequest
+	err := c.ShouldBindUri(&cr)
+	if err != nil {
+		res.FailWithCode(res.ArgumentError, c)
+		return
+	}
+
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*jwts.CustomClaims)
+
+	var commentModel models.CommentModel
+	err = global.DB.Take(&commentModel, cr.ID).Error
+	if err != nil {
+		res.FailWithMessage("评论不存在", c)
+		return
+	}
+	if !(commentModel.UserID == claims.UserID || claims.Role == 1) {
+		res.FailWithMessage("权限错误，不可查看", c)
+		return
+	}
+	list, err := service.ServiceApp.CommentService.HistoryList(commentModel.ID)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("查询失败", c)
+		return
+	}
+	res.OkWithData(list, c)
+}
@@ -0,0 +1,110 @@
+package data_api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/res"
+	"gvb_server/service/digg_ser"
+	"gvb_server/service/es_ser"
+	"gvb_server/service/redis_ser"
+	"gvb_server/utils/timeseries"
+	"time"
+)
+
+// 来源和关键字排行返回的条数
+const analysisTopSize = 10
+
+type DataArticleAnalysisRequest struct {
+	ArticleID   string `form:"article_id" binding:"required" msg:"请选择文章"`
+	From        string `form:"from"`        // 开始时间，默认最近30天
+	To          string `form:"to"`          // 结束时间，默认现在
+	Granularity string `form:"granularity"` // hour day week，默认day
+}
+
+type DataArticleAnalysisResponse struct {
+	Granularity       string               `json:"granularity"`
+	TimeList          []string             `json:"time_list"`           // 每个区间的起点
+	Views             []int                `json:"views"`               // 浏览量
+	UV                []int                `json:"uv"`                  // 访客数
+	Diggs             []int                `json:"diggs"`               // 点赞数
+	Comments          []int                `json:"comments"`            // 评论数
+	Collects          []int                `json:"collects"`            // 收藏数
+	Referrers         []redis_ser.KeyCount `json:"referrers"`           // 来源域名排行
+	Keywords          []redis_ser.KeyCount `json:"keywords"`            // 搜索关键字排行
+	Reading           []redis_ser.KeyCount `json:"reading"`             // 阅读时长分布
+	AvgReadingSeconds int                  `json:"avg_reading_seconds"` // 平均阅读时长，秒
+}
+
+// DataArticleAnalysisView 文章数据分析
+// @Tags 统计管理
+// @Summary 文章数据分析
+// @Description 按小时、天或周统计文章的浏览量、访客数、点赞、评论、收藏，以及来源、搜索关键字和阅读时长分布，按小时统计最多保留一个月
+// @Param data query DataArticleAnalysisRequest    true  "查询参数"
+// @Param token header string true "token"
+// @Router /api/data_article_analysis [get]
+// @Produce json
+// @Success 200 {object} res.Response{data=DataArticleAnalysisResponse}
+func (DataApi) DataArticleAnalysisView(c *gin.Context) {
+	var cr DataArticleAnalysisRequest
+	err := c.ShouldBindQuery(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+	granularity := timeseries.Granularity(cr.Granularity)
+	if cr.Granularity == "" {
+		granularity = timeseries.Day
+	}
+	if !granularity.IsValid() {
+		res.FailWithMessage("统计粒度只能是 hour、day、week", c)
+		return
+	}
+	start, end, err := timeseries.ParseRange(cr.From, cr.To, time.Local, 30)
+	if err != nil {
+		res.FailWithMessage(err.Error(), c)
+		return
+	}
+	timeList, err := granularity.Range(start, end)
+	if err != nil {
+		res.FailWithMessage(err.Error(), c)
+		return
+	}
+	_, err = es_ser.CommeDetail(cr.ArticleID)
+	if err != nil {
+		res.FailWithMessage("文章不存在", c)
+		return
+	}
+
+	series := timeseries.NewSeries(granularity, timeList)
+	// 区间对齐后的整个时间范围
+	rangeStart, rangeEnd := timeList[0], granularity.Next(timeList[len(timeList)-1])
+
+	var diggTimeList, commentTimeList, collectTimeList []time.Time
+	global.DB.Model(&models.UserDiggModel{}).
+		Where("digg_type = ? and target_id = ? and created_at >= ? and created_at < ?", digg_ser.DiggArticle, cr.ArticleID, rangeStart, rangeEnd).
+		Pluck("created_at", &diggTimeList)
+	global.DB.Model(&models.CommentModel{}).
+		Where("article_id = ? and created_at >= ? and created_at < ?", cr.ArticleID, rangeStart, rangeEnd).
+		Pluck("created_at", &commentTimeList)
+	global.DB.Model(&models.UserCollectModel{}).
+		Where("article_id = ? and created_at >= ? and created_at < ?", cr.ArticleID, rangeStart, rangeEnd).
+		Pluck("created_at", &collectTimeList)
+
+	views, uv := redis_ser.GetArticleViewSeries(cr.ArticleID, timeList, string(granularity))
+	lastDay := rangeEnd.Add(-time.Second)
+	reading, avgSeconds := redis_ser.GetArticleReading(cr.ArticleID, rangeStart, lastDay)
+	res.OkWithData(DataArticleAnalysisResponse{
+		Granularity:       string(granularity),
+		TimeList:          series.Labels(),
+		Views:             views,
+		UV:                uv,
+		Diggs:             series.Count(diggTimeList, time.Local),
+		Comments:          series.Count(commentTimeList, time.Local),
+		Collects:          series.Count(collectTimeList, time.Local),
+		Referrers:         redis_ser.GetArticleReferrers(cr.ArticleID, rangeStart, lastDay, analysisTopSize),
+		Keywords:          redis_ser.GetArticleKeywords(cr.ArticleID, rangeStart, lastDay, analysisTopSize),
+		Reading:           reading,
+		AvgReadingSeconds: avgSeconds,
+	}, c)
+}
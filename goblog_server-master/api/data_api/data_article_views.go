@@ -0,0 +1,57 @@
+package data_api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gvb_server/models/res"
+	"gvb_server/service/es_ser"
+	"gvb_server/service/redis_ser"
+	"time"
+)
+
+type DataArticleViewsRequest struct {
+	ArticleID string `form:"article_id" binding:"required" msg:"请选择文章"`
+	Days      int    `form:"days"` // 最近多少天，默认30天
+}
+
+type DataArticleViewsResponse struct {
+	LookCount int                 `json:"look_count"` // 总浏览量
+	UvCount   int                 `json:"uv_count"`   // 总访客数
+	List      []redis_ser.DayView `json:"list"`       // 每天的浏览量和访客数
+}
+
+// DataArticleViewsView 文章每日浏览量
+// @Tags 统计管理
+// @Summary 文章每日浏览量
+// @Description 文章的总浏览量、总访客数，以及最近每天的浏览量和访客数，爬虫不计入
+// @Param data query DataArticleViewsRequest    true  "查询参数"
+// @Param token header string true "token"
+// @Router /api/data_article_views [get]
+// @Produce json
+// @Success 200 {object} res.Response{data=DataArticleViewsResponse}
+func (DataApi) DataArticleViewsView(c *gin.Context) {
+	var cr DataArticleViewsRequest
+	err := c.ShouldBindQuery(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+	if cr.Days <= 0 {
+		cr.Days = 30
+	}
+	if cr.Days > 366 {
+		cr.Days = 366
+	}
+	article, err := es_ser.CommeDetail(cr.ArticleID)
+	if err != nil {
+		res.FailWithMessage("文章不存在", c)
+		return
+	}
+
+	now := time.Now()
+	start := now.AddDate(0, 0, 1-cr.Days)
+	res.OkWithData(DataArticleViewsResponse{
+		LookCount: article.LookCount,
+		UvCount:   article.UvCount,
+		List:      redis_ser.GetArticleDayViews(cr.ArticleID, start, now),
+	}, c)
+}
@@ -3,8 +3,8 @@ package data_api
 import (
 	"github.com/gin-gonic/gin"
 	"gvb_server/global"
-	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/utils/timeseries"
 	"time"
 )
 
@@ -28,42 +28,20 @@ type DateCountResponse struct {
 // @Produce json
 // @Success 200 {object} res.Response{data=DateCountResponse}
 func (DataApi) DataSevenLogin(c *gin.Context) {
-	var loginDateCount, signDateCount []DateCount
-
-	/*	select date_format(created_at, '%Y-%m-%d') as date, count(id) as count
-		from login_data_models
-		where date_sub(curdate(), interval 7 day) <= created_at
-		group by date;	*/
-	global.DB.Model(models.LoginDataModel{}).
-		Where("date_sub(curdate(), interval 7 day)<= created_at").
-		Select("date_format(created_at,'%Y-%m-%d') as date", "count(id) as count").
-		Group("date").
-		Scan(&loginDateCount)
-
-	global.DB.Model(models.UserModel{}).
-		Where("date_sub(curdate(), interval 7 day)<= created_at").
-		Select("date_format(created_at,'%Y-%m-%d') as date", "count(id) as count").
-		Group("date").
-		Scan(&signDateCount)
-
-	var loginDateCountMap = map[string]int{}
-	var signDateCountMap = map[string]int{}
-	var loginCountList, signCountList []int
-	now := time.Now()
-	for _, d := range loginDateCount {
-		loginDateCountMap[d.Date] = d.Count
-	}
-	for _, d := range signDateCount {
-		signDateCountMap[d.Date] = d.Count
+	// 最近7天，按配置的时区对齐到天
+	loc := global.Config.System.Location()
+	now := time.Now().In(loc)
+	timeList, _ := timeseries.Day.Range(now.AddDate(0, 0, -6), now)
+	series := timeseries.NewSeries(timeseries.Day, timeList)
+	loginCountList, err := metricSeries(series, "logins")
+	if err != nil {
+		global.Log.Error(err)
 	}
-	var dateList []string
-	// 7 天内
-	for i := -6; i <= 0; i++ {
-		day := now.AddDate(0, 0, i).Format("2006-01-02")
-		dateList = append(dateList, day)
-		loginCountList = append(loginCountList, loginDateCountMap[day])
-		signCountList = append(signCountList, signDateCountMap[day])
+	signCountList, err := metricSeries(series, "signups")
+	if err != nil {
+		global.Log.Error(err)
 	}
+	dateList := series.Labels()
 
 	res.OkWithData(DateCountResponse{
 		DateList:  dateList,
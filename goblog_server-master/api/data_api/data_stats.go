@@ -0,0 +1,129 @@
+package data_api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/res"
+	"gvb_server/service/article_ser"
+	"gvb_server/service/common"
+	"gvb_server/utils/timeseries"
+	"strings"
+	"time"
+)
+
+// statMetric 按区间边界统计每个区间的数量，相邻的两个边界是一个区间，包含起点不包含终点
+type statMetric func(boundList []time.Time) ([]int, error)
+
+// modelCreatedCount 按created_at统计的表，在数据库里按区间计数
+func modelCreatedCount(model any) statMetric {
+	return func(boundList []time.Time) ([]int, error) {
+		var list = make([]any, 0, len(boundList))
+		for _, bound := range boundList {
+			list = append(list, bound)
+		}
+		return common.ComCountByRange(global.DB.Model(model), "created_at", list)
+	}
+}
+
+var statMetricMap = map[string]statMetric{
+	"logins":   modelCreatedCount(&models.LoginDataModel{}),
+	"signups":  modelCreatedCount(&models.UserModel{}),
+	"comments": modelCreatedCount(&models.CommentModel{}),
+	"messages": modelCreatedCount(&models.MessageModel{}),
+	"chats":    modelCreatedCount(&models.ChatModel{}),
+	"articles": func(boundList []time.Time) ([]int, error) {
+		return article_ser.NewRepository().CountByCreated(boundList)
+	},
+}
+
+type DataStatsRequest struct {
+	Metric      string `form:"metric" binding:"required" msg:"请选择统计项"` // logins signups articles comments messages chats，多个用逗号分隔
+	From        string `form:"from"`                                   // 开始时间，默认最近7天
+	To          string `form:"to"`                                     // 结束时间，默认现在
+	Granularity string `form:"granularity"`                            // hour day week，默认day
+	Timezone    string `form:"timezone"`                               // 时区，例如 Asia/Shanghai，默认使用配置的时区
+}
+
+type DataStatsResponse struct {
+	Granularity string           `json:"granularity"`
+	Timezone    string           `json:"timezone"`
+	TimeList    []string         `json:"time_list"` // 每个区间的起点
+	Series      map[string][]int `json:"series"`    // 统计项 -> 每个区间的数量，和time_list一一对应
+}
+
+// DataStatsView 数据统计
+// @Tags 统计管理
+// @Summary 数据统计
+// @Description 按小时、天或周统计登录、注册、文章、评论、消息、群聊的数量，没有数据的区间为0
+// @Param data query DataStatsRequest    true  "查询参数"
+// @Param token header string true "token"
+// @Router /api/data_stats [get]
+// @Produce json
+// @Success 200 {object} res.Response{data=DataStatsResponse}
+func (DataApi) DataStatsView(c *gin.Context) {
+	var cr DataStatsRequest
+	err := c.ShouldBindQuery(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+	granularity := timeseries.Granularity(cr.Granularity)
+	if cr.Granularity == "" {
+		granularity = timeseries.Day
+	}
+	if !granularity.IsValid() {
+		res.FailWithMessage("统计粒度只能是 hour、day、week", c)
+		return
+	}
+	loc := global.Config.System.Location()
+	if cr.Timezone != "" {
+		loc, err = time.LoadLocation(cr.Timezone)
+		if err != nil {
+			res.FailWithMessage("时区错误", c)
+			return
+		}
+	}
+	var metricList []string
+	for _, metric := range strings.Split(cr.Metric, ",") {
+		metric = strings.TrimSpace(metric)
+		if _, ok := statMetricMap[metric]; !ok {
+			res.FailWithMessage("不支持的统计项 "+metric, c)
+			return
+		}
+		metricList = append(metricList, metric)
+	}
+	start, end, err := timeseries.ParseRange(cr.From, cr.To, loc, 7)
+	if err != nil {
+		res.FailWithMessage(err.Error(), c)
+		return
+	}
+	timeList, err := granularity.Range(start, end)
+	if err != nil {
+		res.FailWithMessage(err.Error(), c)
+		return
+	}
+
+	series := timeseries.NewSeries(granularity, timeList)
+	var data = DataStatsResponse{
+		Granularity: string(granularity),
+		Timezone:    loc.String(),
+		TimeList:    series.Labels(),
+		Series:      map[string][]int{},
+	}
+	for _, metric := range metricList {
+		countList, err := metricSeries(series, metric)
+		if err != nil {
+			global.Log.Error(err)
+			res.FailWithMessage("统计失败", c)
+			return
+		}
+		data.Series[metric] = countList
+	}
+	res.OkWithData(data, c)
+}
+
+// metricSeries 统计项在每个区间的数量
+func metricSeries(series *timeseries.Series, metric string) ([]int, error) {
+	return statMetricMap[metric](series.BoundList())
+}
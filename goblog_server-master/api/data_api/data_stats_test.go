@@ -0,0 +1,64 @@
+package data_api
+
+import (
+	"gvb_server/config"
+	"gvb_server/global"
+	"gvb_server/internal/testdb"
+	"gvb_server/models"
+	"gvb_server/service/article_ser"
+	"gvb_server/utils/timeseries"
+	"testing"
+	"time"
+)
+
+func TestMetricSeriesSqlite(t *testing.T) {
+	db := testdb.Open(t, &models.LoginDataModel{})
+
+	loc := time.Local
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, loc)
+	for _, createdAt := range []time.Time{
+		day.Add(time.Hour),
+		day.Add(23 * time.Hour),
+		day.AddDate(0, 0, 2).Add(time.Minute),
+		day.AddDate(0, 0, 5), // 不在范围内
+	} {
+		db.Create(&models.LoginDataModel{MODEL: models.MODEL{CreatedAt: createdAt}})
+	}
+
+	timeList, _ := timeseries.Day.Range(day, day.AddDate(0, 0, 2))
+	countList, err := metricSeries(timeseries.NewSeries(timeseries.Day, timeList), "logins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{2, 0, 1}
+	if len(countList) != len(want) {
+		t.Fatalf("got %v, want %v", countList, want)
+	}
+	for i := range want {
+		if countList[i] != want[i] {
+			t.Errorf("bucket %d: got %d, want %d", i, countList[i], want[i])
+		}
+	}
+}
+
+func TestArticleSeriesTimezone(t *testing.T) {
+	db := testdb.Open(t, &models.ArticleModel{})
+	global.Config = &config.Config{System: config.System{ArticleStorage: article_ser.StorageMysql}}
+
+	// 文章的created_at是服务器本地时间，统计用的时区和服务器不同
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.Local)
+	db.Create(&models.ArticleModel{ID: "a1", CreatedAt: createdAt.Format("2006-01-02 15:04:05")})
+	loc := time.FixedZone("UTC+14", 14*3600)
+	if _, offset := createdAt.Zone(); offset == 14*3600 {
+		loc = time.FixedZone("UTC-12", -12*3600)
+	}
+	day := timeseries.Day.Truncate(createdAt.In(loc))
+	timeList, _ := timeseries.Day.Range(day, day.AddDate(0, 0, 1))
+	countList, err := metricSeries(timeseries.NewSeries(timeseries.Day, timeList), "articles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(countList) != 2 || countList[0] != 1 || countList[1] != 0 {
+		t.Fatalf("got %v", countList)
+	}
+}
@@ -1,13 +1,14 @@
 package data_api
 
 import (
-	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/olivere/elastic/v7"
 	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service/article_ser"
+	"gvb_server/utils/timeseries"
+	"time"
 )
 
 type DataSumResponse struct {
@@ -31,17 +32,15 @@ func (DataApi) DataSumView(c *gin.Context) {
 	var userCount, articleCount, messageCount, chatGroupCount int
 	var nowLoginCount, nowSignCount int
 
-	result, _ := global.ESClient.
-		Search(models.ArticleModel{}.Index()).
-		Query(elastic.NewMatchAllQuery()).
-		Do(context.Background())
-	articleCount = int(result.Hits.TotalHits.Value) // 搜索到结果总条数
+	articleCount, _ = article_ser.NewRepository().Count()
 	global.DB.Model(models.UserModel{}).Select("count(id)").Scan(&userCount)
 	global.DB.Model(models.MessageModel{}).Select("count(id)").Scan(&messageCount)
 	global.DB.Model(models.ChatModel{ISGroup: true}).Select("count(id)").Scan(&chatGroupCount)
-	global.DB.Model(models.LoginDataModel{}).Where("to_days(created_at)=to_days(now())").
+	// 今天0点，按配置的时区
+	today := timeseries.Day.Truncate(time.Now().In(global.Config.System.Location()))
+	global.DB.Model(models.LoginDataModel{}).Where("created_at >= ?", today).
 		Select("count(id)").Scan(&nowLoginCount)
-	global.DB.Model(models.UserModel{}).Where("to_days(created_at)=to_days(now())").
+	global.DB.Model(models.UserModel{}).Where("created_at >= ?", today).
 		Select("count(id)").Scan(&nowSignCount)
 
 	fmt.Println(userCount, articleCount, messageCount, chatGroupCount, nowLoginCount, nowSignCount)
@@ -0,0 +1,4 @@
+package feed_api
+
+type FeedApi struct {
+}
@@ -0,0 +1,104 @@
+package feed_api
+
+import (
+	"crypto/md5"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/service"
+	"net/http"
+	"time"
+)
+
+const (
+	formatRSS  = "rss"
+	formatAtom = "atom"
+	formatJSON = "json"
+)
+
+var contentTypeMap = map[string]string{
+	formatRSS:  "application/rss+xml; charset=utf-8",
+	formatAtom: "application/atom+xml; charset=utf-8",
+	formatJSON: "application/feed+json; charset=utf-8",
+}
+
+// FeedRSSView rss订阅
+// @Tags 订阅
+// @Summary rss订阅
+// @Description 最新文章的rss 2.0，/tag/{tag}/feed.xml、/category/{category}/feed.xml 按标签、分类订阅
+// @Router /feed.xml [get]
+// @Produce xml
+// @Success 200 {string} string
+func (FeedApi) FeedRSSView(c *gin.Context) {
+	writeFeed(c, formatRSS)
+}
+
+// FeedAtomView atom订阅
+// @Tags 订阅
+// @Summary atom订阅
+// @Description 最新文章的atom 1.0，/tag/{tag}/atom.xml、/category/{category}/atom.xml 按标签、分类订阅
+// @Router /atom.xml [get]
+// @Produce xml
+// @Success 200 {string} string
+func (FeedApi) FeedAtomView(c *gin.Context) {
+	writeFeed(c, formatAtom)
+}
+
+// FeedJSONView json feed订阅
+// @Tags 订阅
+// @Summary json feed订阅
+// @Description 最新文章的json feed 1.1，/tag/{tag}/feed.json、/category/{category}/feed.json 按标签、分类订阅
+// @Router /feed.json [get]
+// @Produce json
+// @Success 200 {string} string
+func (FeedApi) FeedJSONView(c *gin.Context) {
+	writeFeed(c, formatJSON)
+}
+
+// writeFeed 生成订阅，支持 If-None-Match、If-Modified-Since 条件请求
+func writeFeed(c *gin.Context, format string) {
+	feed, err := service.ServiceApp.FeedService.BuildFeed(c.Param("tag"), c.Param("category"), c.Request.URL.Path)
+	if err != nil {
+		global.Log.Error(err)
+		c.String(http.StatusInternalServerError, "订阅生成失败")
+		return
+	}
+
+	var data []byte
+	switch format {
+	case formatAtom:
+		data, err = feed.Atom()
+	case formatJSON:
+		data, err = feed.JSON()
+	default:
+		data, err = feed.RSS()
+	}
+	if err != nil {
+		global.Log.Error(err)
+		c.String(http.StatusInternalServerError, "订阅生成失败")
+		return
+	}
+
+	etag := fmt.Sprintf(`"%x"`, md5.Sum(data))
+	lastModified := feed.Updated.UTC().Truncate(time.Second)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	c.Header("Cache-Control", "public, max-age=600")
+	if notModified(c, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, contentTypeMap[format], data)
+}
+
+// notModified 有If-None-Match时只看etag，否则看If-Modified-Since
+func notModified(c *gin.Context, etag string, lastModified time.Time) bool {
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		return match == etag || match == "*"
+	}
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(since)
+}
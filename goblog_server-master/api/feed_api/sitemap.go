@@ -0,0 +1,67 @@
+package feed_api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/service"
+	"gvb_server/service/feed_ser"
+	"gvb_server/service/redis_ser"
+	"net/http"
+	"strings"
+)
+
+// SitemapView sitemap
+// @Tags 订阅
+// @Summary sitemap
+// @Description 首页、菜单、标签页和已发布文章，超过50000条时返回sitemap索引，缓存在redis中，文章增删改时清除
+// @Router /sitemap.xml [get]
+// @Produce xml
+// @Success 200 {string} string
+func (FeedApi) SitemapView(c *gin.Context) {
+	writeSitemap(c, feed_ser.SitemapIndexKey)
+}
+
+// SitemapPageView 拆分后的sitemap
+// @Tags 订阅
+// @Summary 拆分后的sitemap
+// @Description sitemap超过50000条时拆分的文件
+// @Param page path string true "页码 1.xml"
+// @Router /sitemaps/{page} [get]
+// @Produce xml
+// @Success 200 {string} string
+func (FeedApi) SitemapPageView(c *gin.Context) {
+	writeSitemap(c, strings.TrimSuffix(c.Param("page"), ".xml"))
+}
+
+func writeSitemap(c *gin.Context, key string) {
+	data, ok := redis_ser.GetSitemap(key)
+	if !ok {
+		pageMap, err := service.ServiceApp.FeedService.BuildSitemap()
+		if err != nil {
+			global.Log.Error(err)
+			c.String(http.StatusInternalServerError, "sitemap生成失败")
+			return
+		}
+		err = redis_ser.SetSitemap(pageMap)
+		if err != nil {
+			global.Log.Error(err)
+		}
+		data = pageMap[key]
+	}
+	if len(data) == 0 {
+		c.String(http.StatusNotFound, "sitemap不存在")
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", data)
+}
+
+// RobotsView robots.txt
+// @Tags 订阅
+// @Summary robots.txt
+// @Description robots.txt，指向sitemap
+// @Router /robots.txt [get]
+// @Produce plain
+// @Success 200 {string} string
+func (FeedApi) RobotsView(c *gin.Context) {
+	c.String(http.StatusOK, service.ServiceApp.FeedService.Robots())
+}
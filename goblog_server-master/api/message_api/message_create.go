@@ -5,6 +5,7 @@ import (
 	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service/push_ser"
 )
 
 type MessageRequest struct {
@@ -41,7 +42,7 @@ func (MessageApi) MessageCreateView(c *gin.Context) {
 		res.FailWithMessage("接收人不存在", c)
 	}
 
-	err = global.DB.Create(&models.MessageModel{
+	message := models.MessageModel{
 		SendUserID:       cr.SendUserID,
 		SendUserNickName: sendUser.NickName,
 		SendUserAvatar:   sendUser.Avatar,
@@ -50,12 +51,15 @@ func (MessageApi) MessageCreateView(c *gin.Context) {
 		RevUserAvatar:    recvUser.Avatar,
 		IsRead:           false,
 		Content:          cr.Content,
-	}).Error
+	}
+	err = global.DB.Create(&message).Error
 	if err != nil {
 		global.Log.Error(err)
 		res.FailWithMessage("消息发送失败", c)
 		return
 	}
+	// 接收人在线时实时推送
+	push_ser.Push(message.RevUserID, "message", message)
 	res.OkWithMessage("消息发送成功", c)
 	return
 }
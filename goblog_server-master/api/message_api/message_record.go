@@ -6,6 +6,7 @@ import (
 	"gvb_server/global"
 	"gvb_server/models"
 	"gvb_server/models/res"
+	"gvb_server/service/push_ser"
 	"gvb_server/utils/jwts"
 )
 
@@ -47,6 +48,12 @@ func (MessageApi) MessageRecordView(c *gin.Context) {
 	}
 
 	// 点开消息，里面的每一条消息，都从未读变成已读
+	result := global.DB.Model(&models.MessageModel{}).
+		Where("send_user_id = ? and rev_user_id = ? and is_read = ?", cr.UserID, claims.UserID, false).
+		Update("is_read", true)
+	if result.RowsAffected > 0 {
+		push_ser.PushUnread(claims.UserID)
+	}
 
 	res.OkWithData(messageList, c)
 	return
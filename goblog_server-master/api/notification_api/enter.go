@@ -0,0 +1,4 @@
+package notification_api
+
+type NotificationApi struct {
+}
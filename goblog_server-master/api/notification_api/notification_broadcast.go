@@ -0,0 +1,40 @@
+package notification_api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/models/res"
+	"gvb_server/service"
+)
+
+type NotificationBroadcastRequest struct {
+	Title      string `json:"title" binding:"required" msg:"请输入标题"`
+	Content    string `json:"content" binding:"required" msg:"请输入内容"`
+	UserIDList []uint `json:"user_id_list"` // 不传发给所有用户
+}
+
+// NotificationBroadcastView 发送通知
+// @Tags 通知管理
+// @Summary 发送通知
+// @Description 管理员发送通知，user_id_list为空时作为公告发给所有用户，否则作为系统通知发给指定用户
+// @Param data body NotificationBroadcastRequest    true  "表示多个参数"
+// @Param token header string true "token"
+// @Router /api/notifications/broadcast [post]
+// @Produce json
+// @Success 200 {object} res.Response{}
+func (NotificationApi) NotificationBroadcastView(c *gin.Context) {
+	var cr NotificationBroadcastRequest
+	err := c.ShouldBindJSON(&cr)
+	if err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+	count, err := service.ServiceApp.NotificationService.Broadcast(cr.Title, cr.Content, cr.UserIDList)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("发送失败", c)
+		return
+	}
+	res.OkWithMessage(fmt.Sprintf("已通知 %d 个用户", count), c)
+}
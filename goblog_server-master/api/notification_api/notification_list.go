@@ -0,0 +1,79 @@
+package notification_api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/models/res"
+	"gvb_server/service"
+	"gvb_server/utils/jwts"
+)
+
+type NotificationListRequest struct {
+	models.PageInfo
+	Type   ctype.NotificationType `form:"type"`   // 通知类型，不传查全部
+	Unread bool                   `form:"unread"` // 只看未读
+}
+
+// NotificationListView 我的通知列表
+// @Tags 通知管理
+// @Summary 我的通知列表
+// @Description 我的通知列表，type 1 回复 2 提到 3 点赞 4 收藏 5 系统通知 6 公告
+// @Param data query NotificationListRequest    false  "查询参数"
+// @Param token header string true "token"
+// @Router /api/notifications [get]
+// @Produce json
+// @Success 200 {object} res.Response{data=res.ListResponse[models.NotificationModel]}
+func (NotificationApi) NotificationListView(c *gin.Context) {
+	var cr NotificationListRequest
+	err := c.ShouldBindQuery(&cr)
+	if err != nil {
+		res.FailWithCode(res.ArgumentError, c)
+		return
+	}
+	if cr.Type != 0 && !cr.Type.IsValid() {
+		res.FailWithMessage("通知类型错误", c)
+		return
+	}
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*jwts.CustomClaims)
+
+	list, count, err := service.ServiceApp.NotificationService.List(claims.UserID, cr.Type, cr.Unread, cr.PageInfo)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("查询失败", c)
+		return
+	}
+	res.OkWithList(list, count, c)
+}
+
+type NotificationUnreadResponse struct {
+	Count     int64            `json:"count"`      // 未读总数
+	TypeCount map[string]int64 `json:"type_count"` // 每种类型的未读数
+}
+
+// NotificationUnreadView 未读通知数
+// @Tags 通知管理
+// @Summary 未读通知数
+// @Description 未读通知数，按类型分组
+// @Param token header string true "token"
+// @Router /api/notifications/unread [get]
+// @Produce json
+// @Success 200 {object} res.Response{data=NotificationUnreadResponse}
+func (NotificationApi) NotificationUnreadView(c *gin.Context) {
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*jwts.CustomClaims)
+
+	count, typeCount, err := service.ServiceApp.NotificationService.UnreadCount(claims.UserID)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("查询失败", c)
+		return
+	}
+	response := NotificationUnreadResponse{Count: count, TypeCount: map[string]int64{}}
+	for notifyType, n := range typeCount {
+		response.TypeCount[notifyType.String()] = n
+	}
+	res.OkWithData(response, c)
+}
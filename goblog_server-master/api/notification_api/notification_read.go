@@ -0,0 +1,45 @@
+package notification_api
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/models/res"
+	"gvb_server/service"
+	"gvb_server/service/push_ser"
+	"gvb_server/utils/jwts"
+)
+
+type NotificationReadRequest struct {
+	IDList []uint `json:"id_list"` // 不传标记全部
+}
+
+// NotificationReadView 标记通知已读
+// @Tags 通知管理
+// @Summary 标记通知已读
+// @Description 标记通知已读，id_list为空时全部标记已读
+// @Param data body NotificationReadRequest    true  "表示多个参数"
+// @Param token header string true "token"
+// @Router /api/notifications/read [put]
+// @Produce json
+// @Success 200 {object} res.Response{}
+func (NotificationApi) NotificationReadView(c *gin.Context) {
+	var cr NotificationReadRequest
+	err := c.ShouldBindJSON(&cr)
+	if err != nil {
+		res.FailWithCode(res.ArgumentError, c)
+		return
+	}
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*jwts.CustomClaims)
+
+	count, err := service.ServiceApp.NotificationService.MarkRead(claims.UserID, cr.IDList)
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("标记失败", c)
+		return
+	}
+	// 同一个用户的其他页面同步未读数
+	push_ser.PushUnread(claims.UserID)
+	res.OkWithMessage(fmt.Sprintf("%d 条通知已读", count), c)
+}
@@ -0,0 +1,50 @@
+package notification_api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gvb_server/service/push_ser"
+	"gvb_server/utils/jwts"
+	"time"
+)
+
+// heartbeat 心跳间隔，防止代理断开空闲的连接
+const heartbeat = 30 * time.Second
+
+// NotificationStreamView 实时推送
+// @Tags 通知管理
+// @Summary 实时推送
+// @Description SSE长连接，连上先推一次unread，之后推送 notification 新通知、message 新私信、unread 未读数、broadcast 公告，每30秒一次ping；token可以放在查询参数里
+// @Param token query string false "token"
+// @Param token header string false "token"
+// @Router /api/notifications/stream [get]
+// @Produce text/event-stream
+// @Success 200 {string} string "事件流"
+func (NotificationApi) NotificationStreamView(c *gin.Context) {
+	_claims, _ := c.Get("claims")
+	claims := _claims.(*jwts.CustomClaims)
+
+	client := push_ser.Subscribe(claims.UserID)
+	defer push_ser.Unsubscribe(client)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // nginx不缓存
+
+	c.SSEvent("unread", push_ser.GetUnread(claims.UserID))
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event := <-client.Events:
+			c.SSEvent(event.Event, event.Data)
+		case now := <-ticker.C:
+			c.SSEvent("ping", now.Unix())
+		}
+		c.Writer.Flush()
+	}
+}
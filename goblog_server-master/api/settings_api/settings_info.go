@@ -13,7 +13,7 @@ type SettingsUri struct {
 // SettingsInfoView 显示配置信息
 // @Tags 配置管理
 // @Summary 显示配置信息
-// @Description 显示配置信息 email qq qiniu jwt
+// @Description 显示配置信息 email qq qiniu jwt comment notification chat
 // @Param name path string    true  "name"
 // @Param token header string    true  "token"
 // @Router /api/settings/{name} [get]
@@ -36,6 +36,12 @@ func (s SettingApi) SettingsInfoView(c *gin.Context) {
 		res.OkWithData(global.Config.QiNiu, c)
 	case "jwt":
 		res.OkWithData(global.Config.Jwt, c)
+	case "comment":
+		res.OkWithData(global.Config.Comment, c)
+	case "notification":
+		res.OkWithData(global.Config.Notification, c)
+	case "chat":
+		res.OkWithData(global.Config.Chat, c)
 	default:
 		res.FailWithMessage("没有对应的配置信息", c)
 	}
@@ -6,12 +6,13 @@ import (
 	"gvb_server/core"
 	"gvb_server/global"
 	"gvb_server/models/res"
+	"gvb_server/service/comment_ser"
 )
 
 // SettingsUpdateView 修改配置信息
 // @Tags 配置管理
 // @Summary 修改配置信息
-// @Description 修改配置信息  email qq qiniu jwt
+// @Description 修改配置信息  email qq qiniu jwt comment notification chat
 // @Param data body SettingsUri    true  "配置的一些参数"
 // @Param token header string    true  "token"
 // @Router /api/settings/{name} [put]
@@ -58,6 +59,31 @@ func (s SettingApi) SettingsUpdateView(c *gin.Context) {
 			return
 		}
 		global.Config.Jwt = info
+	case "comment":
+		var info config.Comment
+		err = c.ShouldBindJSON(&info)
+		if err != nil {
+			res.FailWithCode(res.ArgumentError, c)
+			return
+		}
+		global.Config.Comment = info
+		comment_ser.ReloadSensitiveWords()
+	case "notification":
+		var info config.Notification
+		err = c.ShouldBindJSON(&info)
+		if err != nil {
+			res.FailWithCode(res.ArgumentError, c)
+			return
+		}
+		global.Config.Notification = info
+	case "chat":
+		var info config.Chat
+		err = c.ShouldBindJSON(&info)
+		if err != nil {
+			res.FailWithCode(res.ArgumentError, c)
+			return
+		}
+		global.Config.Chat = info
 	default:
 		res.FailWithMessage("没有对应的配置信息", c)
 		return
@@ -0,0 +1,44 @@
+package user_api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/models/res"
+)
+
+type UserTrustLevel struct {
+	TrustLevel ctype.TrustLevel `json:"trust_level" binding:"oneof=0 1 2" msg:"信任等级参数错误"`
+	UserID     uint             `json:"user_id" binding:"required" msg:"用户id错误"`
+}
+
+// UserUpdateTrustLevelView 用户信任等级变更
+// @Tags 用户管理
+// @Summary 用户信任等级变更
+// @Description 用户信任等级变更，0 新用户 1 普通用户 2 信任用户，信任用户的评论跳过审核
+// @Param data body UserTrustLevel    true  "表示多个参数"
+// @Param token header string true "token"
+// @Router /api/user_trust_level [put]
+// @Produce json
+// @Success 200 {object} res.Response{data=string}
+func (UserApi) UserUpdateTrustLevelView(c *gin.Context) {
+	var cr UserTrustLevel
+	if err := c.ShouldBindJSON(&cr); err != nil {
+		res.FailWithError(err, &cr, c)
+		return
+	}
+	var user models.UserModel
+	err := global.DB.Take(&user, cr.UserID).Error
+	if err != nil {
+		res.FailWithMessage("用户id不存在，用户不存在", c)
+		return
+	}
+	err = global.DB.Model(&user).Update("trust_level", cr.TrustLevel).Error
+	if err != nil {
+		global.Log.Error(err)
+		res.FailWithMessage("修改信任等级失败", c)
+		return
+	}
+	res.OkWithMessage("修改信任等级成功", c)
+}
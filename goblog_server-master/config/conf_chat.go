@@ -0,0 +1,32 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Chat 群聊
+type Chat struct {
+	AllowOrigins []string `json:"allow_origins" yaml:"allow_origins"` // 允许连接群聊的来源，例如 https://blog.example.com，* 表示全部允许，不配置时只允许同源
+}
+
+// CheckOrigin 来源是否允许，host是请求的Host，用于不配置时的同源判断
+func (c Chat) CheckOrigin(origin, host string) bool {
+	// 不是浏览器发起的连接没有Origin
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if len(c.AllowOrigins) == 0 {
+		return strings.EqualFold(u.Host, host)
+	}
+	for _, allow := range c.AllowOrigins {
+		if allow == "*" || strings.EqualFold(strings.TrimRight(allow, "/"), u.Scheme+"://"+u.Host) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package config
+
+// Comment 评论审核和编辑
+type Comment struct {
+	Moderation     bool     `json:"moderation" yaml:"moderation"`           // 是否开启审核，关闭时评论直接通过，只做频率限制
+	SensitiveWords []string `json:"sensitive_words" yaml:"sensitive_words"` // 敏感词，命中的评论进入待审核
+	SensitiveFile  string   `json:"sensitive_file" yaml:"sensitive_file"`   // 敏感词文件，一行一个，和sensitive_words合并
+	MaxLinks       int      `json:"max_links" yaml:"max_links"`             // 链接数超过这个值算垃圾评论，默认2
+	RepeatWindow   int      `json:"repeat_window" yaml:"repeat_window"`     // 多少秒内发相同的内容算垃圾评论，默认600
+	RateLimit      int      `json:"rate_limit" yaml:"rate_limit"`           // 每个用户每分钟最多发几条评论，默认5
+	TrustApproved  int      `json:"trust_approved" yaml:"trust_approved"`   // 新用户通过审核多少条评论后不再需要审核，默认3
+	EditWindow     int      `json:"edit_window" yaml:"edit_window"`         // 发表后多少秒内可以编辑，默认900
+}
+
+func (c Comment) GetMaxLinks() int {
+	if c.MaxLinks <= 0 {
+		return 2
+	}
+	return c.MaxLinks
+}
+
+func (c Comment) GetRepeatWindow() int {
+	if c.RepeatWindow <= 0 {
+		return 600
+	}
+	return c.RepeatWindow
+}
+
+func (c Comment) GetRateLimit() int {
+	if c.RateLimit <= 0 {
+		return 5
+	}
+	return c.RateLimit
+}
+
+func (c Comment) GetTrustApproved() int {
+	if c.TrustApproved <= 0 {
+		return 3
+	}
+	return c.TrustApproved
+}
+
+func (c Comment) GetEditWindow() int {
+	if c.EditWindow <= 0 {
+		return 900
+	}
+	return c.EditWindow
+}
@@ -1,21 +1,54 @@
 package config
 
 import (
+	"fmt"
 	"strconv"
 )
 
 type Mysql struct {
+	Driver   string `yaml:"driver"` // 数据库类型 mysql postgres sqlite，默认mysql
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"`
-	Config   string `yaml:"config"` // 高级配置，例如 charset
-	DB       string `yaml:"db"`
+	Config   string `yaml:"config"` // 高级配置，例如 charset，postgres为 sslmode=disable TimeZone=Asia/Shanghai 这种格式
+	DB       string `yaml:"db"`     // 数据库名，sqlite为数据库文件的路径，:memory: 为内存数据库
 	User     string `yaml:"user"`
 	Password string `yaml:"password"`
 	LogLevel string `yaml:"log_Level"` // 日志等级，debug就是输出全部sql,dev,release
 }
 
+const (
+	DriverMysql    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSqlite   = "sqlite"
+)
+
+// GetDriver 数据库类型，没配置的默认mysql
+func (m Mysql) GetDriver() string {
+	if m.Driver == "" {
+		return DriverMysql
+	}
+	return m.Driver
+}
+
+// IsEnabled 是否配置了数据库，sqlite只需要配置文件路径
+func (m Mysql) IsEnabled() bool {
+	if m.GetDriver() == DriverSqlite {
+		return m.DB != ""
+	}
+	return m.Host != ""
+}
+
 // Dsn 数据源
 func (m Mysql) Dsn() string {
+	switch m.GetDriver() {
+	case DriverPostgres:
+		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s %s", m.Host, m.Port, m.User, m.Password, m.DB, m.Config)
+	case DriverSqlite:
+		if m.Config == "" {
+			return m.DB
+		}
+		return m.DB + "?" + m.Config
+	}
 	dsn := m.User + ":" + m.Password + "@tcp(" + m.Host + ":" + strconv.Itoa(m.Port) + ")/" + m.DB + "?" + m.Config
 	return dsn
 }
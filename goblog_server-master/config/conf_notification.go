@@ -0,0 +1,14 @@
+package config
+
+// Notification 站内通知
+type Notification struct {
+	EmailDigest bool `json:"email_digest" yaml:"email_digest"` // 是否每天把未读通知汇总发到用户绑定的邮箱
+	DigestHour  int  `json:"digest_hour" yaml:"digest_hour"`   // 每天几点发邮件摘要，0-23，默认8
+}
+
+func (n Notification) GetDigestHour() int {
+	if n.DigestHour <= 0 || n.DigestHour > 23 {
+		return 8
+	}
+	return n.DigestHour
+}
@@ -1,15 +1,33 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type System struct {
-	Host   string `yaml:"host"`
-	Port   int    `yaml:"port"`
-	Env    string `yaml:"env"`
-	SslPem string `yaml:"ssl-pem"`
-	SslKey string `yaml:"ssl-key"`
+	Host           string `yaml:"host"`
+	Port           int    `yaml:"port"`
+	Env            string `yaml:"env"`
+	SslPem         string `yaml:"ssl-pem"`
+	SslKey         string `yaml:"ssl-key"`
+	ArticleStorage string `yaml:"article-storage"` // 文章存储 es mysql，默认es
+	SpaIndex       string `yaml:"spa-index"`       // 前端打包后的index.html，文章页的meta会注入到这个文件里
+	Timezone       string `yaml:"timezone"`        // 数据统计使用的时区，例如 Asia/Shanghai，默认服务器本地时区
 }
 
 func (s System) Addr() string {
 	return fmt.Sprintf("%s:%d", s.Host, s.Port)
 }
+
+// Location 数据统计使用的时区，没配置或配置错误时用服务器本地时区
+func (s System) Location() *time.Location {
+	if s.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
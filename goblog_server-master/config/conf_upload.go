@@ -1,6 +1,15 @@
 package config
 
 type Upload struct {
-	Size int    `yaml:"size" json:"size"` // 图片上传的大小
-	Path string `yaml:"path" json:"path"` // 图片上传的目录
+	Size       int    `yaml:"size" json:"size"`               // 图片上传的大小
+	Path       string `yaml:"path" json:"path"`               // 图片上传的目录
+	ImportSize int    `yaml:"import_size" json:"import_size"` // 导入文章时单个markdown文件的大小，单位MB，默认2
+}
+
+// GetImportSize 导入文章时单个markdown文件的最大字节数
+func (u Upload) GetImportSize() int64 {
+	if u.ImportSize <= 0 {
+		return 2 << 20
+	}
+	return int64(u.ImportSize) << 20
 }
@@ -3,18 +3,20 @@ package core
 import (
 	"fmt"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"gvb_server/config"
 	"gvb_server/global"
 	"time"
 )
 
 func InitGorm() *gorm.DB {
-	if global.Config.Mysql.Host == "" {
+	if !global.Config.Mysql.IsEnabled() {
 		global.Log.Warnln("未配置mysql，取消gorm连接")
 		return nil
 	}
-	dsn := global.Config.Mysql.Dsn()
 
 	var mysqlLogger logger.Interface
 	if global.Config.System.Env == "debug" {
@@ -24,17 +26,49 @@ func InitGorm() *gorm.DB {
 	}
 	global.MysqlLog = logger.Default.LogMode(logger.Info)
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger:                                   mysqlLogger,
+	db, err := OpenGorm(global.Config.Mysql, mysqlLogger)
+	if err != nil {
+		global.Log.Fatalf(fmt.Sprintf("[%s] %s连接失败 %s", global.Config.Mysql.Dsn(), global.Config.Mysql.GetDriver(), err))
+	}
+	return db
+}
+
+// Dialector 根据配置的数据库类型选择驱动
+func Dialector(conf config.Mysql) (gorm.Dialector, error) {
+	dsn := conf.Dsn()
+	switch conf.GetDriver() {
+	case config.DriverMysql:
+		return mysql.Open(dsn), nil
+	case config.DriverPostgres:
+		return postgres.Open(dsn), nil
+	case config.DriverSqlite:
+		return sqlite.Open(dsn), nil
+	}
+	return nil, fmt.Errorf("不支持的数据库类型 %s", conf.Driver)
+}
+
+// OpenGorm 打开数据库连接，测试时可以用sqlite的内存数据库
+func OpenGorm(conf config.Mysql, gormLogger logger.Interface) (*gorm.DB, error) {
+	dialector, err := Dialector(conf)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:                                   gormLogger,
 		DisableForeignKeyConstraintWhenMigrating: true, // 忽略外键检查
 	})
 	if err != nil {
-		global.Log.Fatalf(fmt.Sprintf("[%s] mysql连接失败", dsn))
+		return nil, err
 	}
 
 	sqlDB, _ := db.DB()
+	if conf.GetDriver() == config.DriverSqlite {
+		// sqlite同一时间只能有一个写连接，内存数据库每个连接都是独立的库
+		sqlDB.SetMaxOpenConns(1)
+		return db, nil
+	}
 	sqlDB.SetMaxIdleConns(10)               // 最大空闲连接数
 	sqlDB.SetMaxOpenConns(100)              //最多可容纳
 	sqlDB.SetConnMaxLifetime(time.Hour * 4) // 连接最大复用时间，不能超过mysql的wait_timeout
-	return db
+	return db, nil
 }
@@ -1,36 +1,65 @@
 package flag
 
 import (
+	"fmt"
 	"gvb_server/global"
-	"gvb_server/models"
+	"gvb_server/migrations"
+	"strconv"
 )
 
-// Makemigrations 迁移表
-func Makemigrations() {
-	var err error
-	//global.DB.SetupJoinTable(&models.UserModel{}, "CollectsModels", &models.UserCollectModel{})
-	global.DB.SetupJoinTable(&models.MenuModel{}, "Banners", &models.MenuBannerModel{})
-	err = global.DB.Set("gorm:table_options", "ENGINE=InnoDB").
-		AutoMigrate(
-			//&models.BannerModel{},
-			//&models.TagModel{},
-			//&models.MessageModel{},
-			//&models.AdvertModel{},
-			//&models.UserModel{},
-			//&models.CommentModel{},
-			&models.ArticleModel{},
-			//&models.UserCollectModel{},
-			//&models.MenuModel{},
-			//&models.MenuBannerModel{},
-			//&models.FadeBackModel{},
-			//&models.LoginDataModel{},
-			//&models.ChatModel{},
-			//&log_stash.LogStashModel{},
-		)
+// DBMigrate 执行所有没有执行过的迁移
+func DBMigrate() {
+	doneList, err := migrations.Migrate(global.DB)
+	for _, migration := range doneList {
+		global.Log.Infof("[ success ] %d_%s", migration.Version, migration.Name)
+	}
+	if err != nil {
+		global.Log.Errorf("[ error ] %s", err)
+		return
+	}
+	if len(doneList) == 0 {
+		global.Log.Infof("数据库已是最新版本")
+		return
+	}
+	global.Log.Infof("[ success ] 执行迁移 %d 个", len(doneList))
+}
+
+// DBRollback 回滚最近执行的n个迁移，默认1个
+func DBRollback(arg string) {
+	n := 1
+	if arg != "" {
+		var err error
+		n, err = strconv.Atoi(arg)
+		if err != nil {
+			global.Log.Errorf("回滚数量错误 %s", arg)
+			return
+		}
+	}
+	doneList, err := migrations.Rollback(global.DB, n)
+	for _, migration := range doneList {
+		global.Log.Infof("[ success ] 回滚 %d_%s", migration.Version, migration.Name)
+	}
 	if err != nil {
-		global.Log.Error("[ error ] 生成数据库表结构失败！")
+		global.Log.Errorf("[ error ] %s", err)
 		return
 	}
-	global.Log.Infof("[ success ] 生成数据库表结构成功！")
+	if len(doneList) == 0 {
+		global.Log.Infof("没有可以回滚的迁移")
+	}
+}
 
+// DBStatus 打印每个迁移的执行状态
+func DBStatus() {
+	list, err := migrations.StatusList(global.DB)
+	if err != nil {
+		global.Log.Errorf("[ error ] %s", err)
+		return
+	}
+	for _, status := range list {
+		appliedAt := "未执行"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%4d  %-32s %s\n", status.Version, status.Name, appliedAt)
+	}
 }
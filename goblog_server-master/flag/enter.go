@@ -2,27 +2,38 @@ package flag
 
 import (
 	sys_flag "flag"
+	"gvb_server/core"
+	"gvb_server/global"
 
 	"github.com/fatih/structs"
 )
 
 type Option struct {
-	DB   bool
-	User string // -u admin -u user
-	ES   string // -es create -es delete
+	DB     string // -db migrate -db rollback N -db status
+	User   string // -u admin -u user
+	ES     string // -es create -es reindex -es rollback -es delete -es dump file -es load file
+	Import string // -import posts.zip 导入markdown文章
+	Export string // -export ./static 导出静态站点
+	Seed   string // -seed 10 或 -seed users=10,articles=20,comments=5,messages=30,chats=50 生成演示数据
 }
 
 // Parse 解析命令行参数
 func Parse() Option {
-	db := sys_flag.Bool("db", false, "初始化数据库")
+	db := sys_flag.String("db", "", "数据库迁移 migrate rollback status")
 	user := sys_flag.String("u", "", "创建用户")
-	es := sys_flag.String("es", "", "es操作")
+	es := sys_flag.String("es", "", "es操作 create reindex rollback delete dump load")
+	importPath := sys_flag.String("import", "", "导入markdown文章，目录、zip或单个文件")
+	exportPath := sys_flag.String("export", "", "导出静态站点到目录")
+	seed := sys_flag.String("seed", "", "生成演示数据，例如 10 或 users=10,articles=20,comments=5,messages=30,chats=50")
 	// 解析命令行参数写入注册的flag里
 	sys_flag.Parse()
 	return Option{
-		DB:   *db,
-		User: *user,
-		ES:   *es,
+		DB:     *db,
+		User:   *user,
+		ES:     *es,
+		Import: *importPath,
+		Export: *exportPath,
+		Seed:   *seed,
 	}
 }
 
@@ -46,8 +57,21 @@ func IsWebStop(option Option) (f bool) {
 
 // SwitchOption 根据命令执行不同的函数
 func SwitchOption(option Option) {
-	if option.DB {
-		Makemigrations()
+	if option.DB != "" {
+		if global.DB == nil {
+			global.Log.Errorf("未配置数据库")
+			return
+		}
+		switch option.DB {
+		case "migrate":
+			DBMigrate()
+		case "rollback":
+			DBRollback(sys_flag.Arg(0))
+		case "status":
+			DBStatus()
+		default:
+			sys_flag.Usage()
+		}
 		return
 	}
 
@@ -56,11 +80,43 @@ func SwitchOption(option Option) {
 		return
 	}
 
-	// if option.ES == "create" {
-	// 	// 连接es
-	// 	global.ESClient = core.EsConnect()
-	// 	EsCreateIndex()
-	// }
+	if option.Import != "" {
+		ImportArticle(option.Import)
+		return
+	}
+
+	if option.Export != "" {
+		ExportStatic(option.Export)
+		return
+	}
+
+	if option.Seed != "" {
+		SeedData(option.Seed)
+		return
+	}
+
+	if option.ES != "" {
+		// 连接es
+		global.ESClient = core.EsConnect()
+		switch option.ES {
+		case "create":
+			EsCreateIndex()
+		case "reindex":
+			EsReindex()
+		case "rollback":
+			EsRollback()
+		case "delete":
+			EsRemoveIndex()
+		case "dump":
+			EsDump(sys_flag.Arg(0))
+		case "load":
+			EsLoad(sys_flag.Arg(0))
+		default:
+			sys_flag.Usage()
+		}
+		return
+	}
+
 	//fmt.Printf("%#v\n", option.User)
 	//sys_flag.Usage()
 
@@ -1,8 +1,64 @@
 package flag
 
-import "gvb_server/models"
+import (
+	"gvb_server/global"
+	"gvb_server/models"
+)
 
+// esIndexModel 存在es里的模型，业务里用的索引名都是别名
+type esIndexModel interface {
+	Index() string
+	CreateIndex() error
+	Reindex() error
+	RollbackIndex() error
+	RemoveIndex() error
+}
+
+var esIndexModelList = []esIndexModel{
+	models.ArticleModel{},
+	models.FullTextModel{},
+}
+
+// EsCreateIndex 创建索引，已存在的跳过
 func EsCreateIndex() {
-	models.ArticleModel{}.CreateIndex()
-	models.FullTextModel{}.CreateIndex()
+	for _, model := range esIndexModelList {
+		err := model.CreateIndex()
+		if err != nil {
+			global.Log.Errorf("[ error ] 创建索引 %s 失败 %s", model.Index(), err)
+		}
+	}
+}
+
+// EsReindex 修改mapping后平滑迁移，读写一直走别名，迁移期间只读
+func EsReindex() {
+	for _, model := range esIndexModelList {
+		err := model.Reindex()
+		if err != nil {
+			global.Log.Errorf("[ error ] 迁移索引 %s 失败 %s", model.Index(), err)
+			continue
+		}
+		global.Log.Infof("[ success ] 迁移索引 %s 成功", model.Index())
+	}
+}
+
+// EsRollback 别名切回上一个版本
+func EsRollback() {
+	for _, model := range esIndexModelList {
+		err := model.RollbackIndex()
+		if err != nil {
+			global.Log.Errorf("[ error ] 回滚索引 %s 失败 %s", model.Index(), err)
+			continue
+		}
+		global.Log.Infof("[ success ] 回滚索引 %s 成功", model.Index())
+	}
+}
+
+// EsRemoveIndex 删除索引及其所有版本
+func EsRemoveIndex() {
+	for _, model := range esIndexModelList {
+		err := model.RemoveIndex()
+		if err != nil {
+			global.Log.Errorf("[ error ] 删除索引 %s 失败 %s", model.Index(), err)
+		}
+	}
 }
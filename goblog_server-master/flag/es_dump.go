@@ -0,0 +1,186 @@
+package flag
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/olivere/elastic/v7"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/service/es_ser"
+	"io"
+	"os"
+)
+
+// 每批写入es的文章数
+const esLoadBatchSize = 200
+
+// EsDump 把文章导出为JSON Lines，一行一篇，保留原来的id
+func EsDump(file string) {
+	if file == "" {
+		global.Log.Error("[ error ] 请指定导出文件 -es dump articles.jsonl")
+		return
+	}
+	f, err := os.Create(file)
+	if err != nil {
+		global.Log.Errorf("[ error ] 创建文件失败 %s", err)
+		return
+	}
+	writer := bufio.NewWriter(f)
+	count, err := esDumpArticles(writer)
+	// 写入、刷盘、关闭文件任何一步失败，导出的文件都不完整
+	if err == nil {
+		err = writer.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		global.Log.Errorf("[ error ] 导出文章失败，已导出 %d 篇，文件 %s 不完整 %s", count, file, err)
+		return
+	}
+	global.Log.Infof("[ success ] 导出文章 %d 篇至 %s", count, file)
+}
+
+// esDumpArticles 滚动读取全部文章写入writer，返回写入的文章数
+func esDumpArticles(writer *bufio.Writer) (count int, err error) {
+	scroll := global.ESClient.Scroll(models.ArticleModel{}.Index()).
+		Query(elastic.NewMatchAllQuery()).
+		Size(500).
+		KeepAlive("1m")
+	defer scroll.Clear(context.Background())
+
+	for {
+		result, err := scroll.Do(context.Background())
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, fmt.Errorf("读取文章失败 %w", err)
+		}
+		for _, hit := range result.Hits.Hits {
+			// 用map保留es里的全部字段
+			var doc map[string]any
+			err = json.Unmarshal(hit.Source, &doc)
+			if err != nil {
+				global.Log.Error(err)
+				continue
+			}
+			doc["id"] = hit.Id
+			line, err := json.Marshal(doc)
+			if err != nil {
+				return count, err
+			}
+			if _, err = writer.Write(line); err != nil {
+				return count, err
+			}
+			if err = writer.WriteByte('\n'); err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+}
+
+// EsLoad 导入EsDump导出的文件，按原id写入文章，并重建全文搜索
+func EsLoad(file string) {
+	if file == "" {
+		global.Log.Error("[ error ] 请指定导入文件 -es load articles.jsonl")
+		return
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		global.Log.Errorf("[ error ] 打开文件失败 %s", err)
+		return
+	}
+	defer f.Close()
+
+	// 索引不存在时先创建
+	EsCreateIndex()
+
+	reader := bufio.NewReader(f)
+	var docList []map[string]any
+	var count, lineNo int
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			lineNo++
+			var doc map[string]any
+			if jsonErr := json.Unmarshal(line, &doc); jsonErr != nil {
+				global.Log.Errorf("第 %d 行解析失败 %s", lineNo, jsonErr)
+			} else if id, _ := doc["id"].(string); id == "" {
+				global.Log.Errorf("第 %d 行缺少id", lineNo)
+			} else {
+				docList = append(docList, doc)
+			}
+		}
+		if len(docList) >= esLoadBatchSize || (err != nil && len(docList) > 0) {
+			count += esLoadBatch(docList)
+			docList = nil
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			global.Log.Errorf("[ error ] 读取文件失败 %s", err)
+			return
+		}
+	}
+	global.Log.Infof("[ success ] 导入文章 %d 篇", count)
+}
+
+// esLoadBatch 批量写入文章和全文搜索数据，返回写入成功的文章数
+func esLoadBatch(docList []map[string]any) int {
+	articleIndex := models.ArticleModel{}.Index()
+	fullTextIndex := models.FullTextModel{}.Index()
+
+	// 先删掉这批文章旧的全文搜索数据，避免重复导入时重复
+	var idList []any
+	var idMap = map[string]bool{}
+	for _, doc := range docList {
+		idList = append(idList, doc["id"])
+		idMap[doc["id"].(string)] = true
+	}
+	_, err := global.ESClient.DeleteByQuery().
+		Index(fullTextIndex).
+		Query(elastic.NewTermsQuery("key", idList...)).
+		Refresh("true").
+		Do(context.Background())
+	if err != nil {
+		global.Log.Error(err)
+	}
+
+	bulk := global.ESClient.Bulk().Refresh("true")
+	for _, doc := range docList {
+		id := doc["id"].(string)
+		bulk.Add(elastic.NewBulkIndexRequest().Index(articleIndex).Id(id).Doc(doc))
+
+		var article models.ArticleModel
+		line, _ := json.Marshal(doc)
+		_ = json.Unmarshal(line, &article)
+		if !article.Status.IsPublished() {
+			continue
+		}
+		for _, data := range es_ser.GetSearchIndexDataByContent(id, article.Title, article.Content) {
+			bulk.Add(elastic.NewBulkIndexRequest().Index(fullTextIndex).Doc(data))
+		}
+	}
+	result, err := bulk.Do(context.Background())
+	if err != nil {
+		global.Log.Errorf("批量写入失败 %s", err)
+		return 0
+	}
+	// 返回的是别名背后的物理索引名，按id区分文章
+	var count int
+	for _, item := range result.Succeeded() {
+		if idMap[item.Id] {
+			count++
+		}
+	}
+	for _, item := range result.Failed() {
+		global.Log.Errorf("写入 %s 失败 %v", item.Id, item.Error)
+	}
+	return count
+}
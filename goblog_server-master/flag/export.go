@@ -0,0 +1,21 @@
+package flag
+
+import (
+	"gvb_server/core"
+	"gvb_server/global"
+	"gvb_server/service"
+	"gvb_server/service/article_ser"
+)
+
+// ExportStatic 导出静态站点，api不可用时可以放到cdn上作为只读镜像
+func ExportStatic(dir string) {
+	if article_ser.IsES() {
+		global.ESClient = core.EsConnect()
+	}
+	count, err := service.ServiceApp.StaticService.Export(dir)
+	if err != nil {
+		global.Log.Errorf("[ error ] 导出静态站点失败 %s", err)
+		return
+	}
+	global.Log.Infof("[ success ] 导出静态站点至 %s，共 %d 个页面", dir, count)
+}
@@ -0,0 +1,75 @@
+package flag
+
+import (
+	"gvb_server/core"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/service"
+	"gvb_server/service/article_ser"
+	"gvb_server/service/import_ser"
+	"gvb_server/service/redis_ser"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImportArticle 导入目录或zip里的markdown文章，作者为第一个管理员
+func ImportArticle(path string) {
+	if article_ser.IsES() {
+		global.ESClient = core.EsConnect()
+	}
+	global.Redis = core.ConnectRedis()
+	var admin models.UserModel
+	err := global.DB.Take(&admin, "role = ?", ctype.PermissionAdmin).Error
+	if err != nil {
+		global.Log.Error("[ error ] 没有管理员，请先执行 -u admin 创建")
+		return
+	}
+	author := import_ser.Author{
+		UserID:   admin.ID,
+		NickName: admin.NickName,
+		Avatar:   admin.Avatar,
+	}
+
+	importService := service.ServiceApp.ImportService
+	var resultList []import_ser.ImportResult
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		global.Log.Errorf("[ error ] %s", err)
+		return
+	case info.IsDir():
+		resultList, err = importService.ImportDir(path, author)
+	case strings.ToLower(filepath.Ext(path)) == ".zip":
+		var file *os.File
+		file, err = os.Open(path)
+		if err != nil {
+			break
+		}
+		resultList, err = importService.ImportZip(file, info.Size(), author)
+		file.Close()
+	default:
+		var data []byte
+		data, err = os.ReadFile(path)
+		if err != nil {
+			break
+		}
+		resultList = append(resultList, importService.ImportFile(filepath.Base(path), data, author))
+	}
+	if err != nil {
+		global.Log.Errorf("[ error ] %s", err)
+	}
+
+	var successCount int
+	for _, result := range resultList {
+		if result.IsSuccess {
+			successCount++
+			global.Log.Infof("[ success ] %s %s", result.File, result.Title)
+			continue
+		}
+		global.Log.Errorf("[ error ] %s %s", result.File, result.Msg)
+	}
+	redis_ser.ClearSitemap()
+	global.Log.Infof("导入完成，成功 %d 篇，失败 %d 篇", successCount, len(resultList)-successCount)
+}
@@ -0,0 +1,35 @@
+package flag
+
+import (
+	"gvb_server/core"
+	"gvb_server/global"
+	"gvb_server/service"
+	"gvb_server/service/article_ser"
+	"gvb_server/service/redis_ser"
+	"gvb_server/service/seed_ser"
+)
+
+// SeedData 生成演示数据，用于本地开发和截图
+func SeedData(value string) {
+	if global.DB == nil {
+		global.Log.Errorf("未配置数据库")
+		return
+	}
+	option, err := seed_ser.ParseOption(value)
+	if err != nil {
+		global.Log.Errorf("[ error ] %s", err)
+		return
+	}
+	if article_ser.IsES() {
+		global.ESClient = core.EsConnect()
+	}
+	global.Redis = core.ConnectRedis()
+
+	result, err := service.ServiceApp.SeedService.Seed(option)
+	if err != nil {
+		global.Log.Errorf("[ error ] %s", err)
+	}
+	redis_ser.ClearSitemap()
+	global.Log.Infof("生成用户 %d 个（密码 %s）、标签 %d 个、文章 %d 篇、评论 %d 条、消息 %d 条、群聊 %d 条",
+		result.Users, seed_ser.Password, result.Tags, result.Articles, result.Comments, result.Messages, result.Chats)
+}
@@ -0,0 +1,34 @@
+package testdb
+
+import (
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gvb_server/config"
+	"gvb_server/core"
+	"gvb_server/global"
+	"testing"
+)
+
+// Open 测试用的sqlite内存数据库，建好传入的表后设置为全局的数据库和日志，测试结束后还原
+// 不会改全局配置，需要配置的测试自己设置
+func Open(t testing.TB, modelList ...any) *gorm.DB {
+	t.Helper()
+	db, err := core.OpenGorm(config.Mysql{Driver: config.DriverSqlite, DB: ":memory:"}, logger.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(modelList) > 0 {
+		err = db.AutoMigrate(modelList...)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldDB, oldLog := global.DB, global.Log
+	t.Cleanup(func() {
+		global.DB, global.Log = oldDB, oldLog
+	})
+	global.DB = db
+	global.Log = logrus.New()
+	return db
+}
@@ -6,7 +6,10 @@ import (
 	"gvb_server/flag"
 	"gvb_server/global"
 	"gvb_server/routers"
+	"gvb_server/service/article_ser"
+	"gvb_server/service/chat_ser"
 	"gvb_server/service/cron_ser"
+	"gvb_server/service/push_ser"
 	"gvb_server/utils"
 )
 
@@ -35,8 +38,14 @@ func main() {
 
 	// 连接redis
 	global.Redis = core.ConnectRedis()
-	// 连接es
-	global.ESClient = core.EsConnect()
+	// 连接es，文章存在mysql时不需要es
+	if article_ser.IsES() {
+		global.ESClient = core.EsConnect()
+	}
+
+	// 订阅其他实例的实时推送和群聊消息
+	go push_ser.Run()
+	go chat_ser.Run()
 
 	// 定时任务，同步redis数据至es和mysql
 	cron_ser.CronInit()
@@ -65,3 +65,62 @@ func JwtAdmin() gin.HandlerFunc {
 		c.Set("claims", claims)
 	}
 }
+
+// streamToken 长连接的token，浏览器的EventSource和WebSocket不能设置请求头，token也可以放在查询参数里
+func streamToken(c *gin.Context) string {
+	token := c.Request.Header.Get("token")
+	if token == "" {
+		token = c.Query("token")
+	}
+	return token
+}
+
+// JwtStream 用于SSE等长连接，token可以放在查询参数里
+func JwtStream() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := streamToken(c)
+		if token == "" {
+			res.FailWithMessage("未携带token", c)
+			c.Abort()
+			return
+		}
+		claims, err := jwts.ParseToken(token)
+		if err != nil {
+			res.FailWithMessage("token错误", c)
+			c.Abort()
+			return
+		}
+		// 判断是否在redis中
+		if redis_ser.CheckLogout(token) {
+			res.FailWithMessage("token已失效", c)
+			c.Abort()
+			return
+		}
+		c.Set("claims", claims)
+	}
+}
+
+// GetClaims 解析请求头中可选的token，未登录或token失效返回nil
+func GetClaims(c *gin.Context) *jwts.CustomClaims {
+	return parseOptionalToken(c.GetHeader("token"))
+}
+
+// GetStreamClaims 长连接可选的token，可以放在查询参数里
+func GetStreamClaims(c *gin.Context) *jwts.CustomClaims {
+	return parseOptionalToken(streamToken(c))
+}
+
+func parseOptionalToken(token string) *jwts.CustomClaims {
+	if token == "" {
+		return nil
+	}
+	claims, err := jwts.ParseToken(token)
+	if err != nil {
+		return nil
+	}
+	// 判断是否在redis中
+	if redis_ser.CheckLogout(token) {
+		return nil
+	}
+	return claims
+}
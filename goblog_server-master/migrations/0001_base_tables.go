@@ -0,0 +1,197 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 项目最初的表
+var baseTables = Migration{
+	Version: 1,
+	Name:    "base_tables",
+	Up: func(tx *gorm.DB) error {
+		return createTables(tx,
+			&bannerModelV1{},
+			&tagModelV1{},
+			&messageModelV1{},
+			&advertModelV1{},
+			&userModelV1{},
+			&commentModelV1{},
+			&userCollectModelV1{},
+			&menuModelV1{},
+			&menuBannerModelV1{},
+			&fadeBackModelV1{},
+			&loginDataModelV1{},
+			&chatModelV1{},
+			&logStashModelV1{},
+		)
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx,
+			&logStashModelV1{},
+			&chatModelV1{},
+			&loginDataModelV1{},
+			&fadeBackModelV1{},
+			&menuBannerModelV1{},
+			&menuModelV1{},
+			&userCollectModelV1{},
+			&commentModelV1{},
+			&userModelV1{},
+			&advertModelV1{},
+			&messageModelV1{},
+			&tagModelV1{},
+			&bannerModelV1{},
+		)
+	},
+}
+
+type bannerModelV1 struct {
+	Model     model `gorm:"embedded"`
+	Path      string
+	Hash      string
+	Name      string `gorm:"size:38"`
+	ImageType int    `gorm:"default:1"`
+}
+
+func (bannerModelV1) TableName() string { return "banner_models" }
+
+type tagModelV1 struct {
+	Model model  `gorm:"embedded"`
+	Title string `gorm:"size:16"`
+}
+
+func (tagModelV1) TableName() string { return "tag_models" }
+
+type messageModelV1 struct {
+	Model            model  `gorm:"embedded"`
+	SendUserID       uint   `gorm:"primaryKey"`
+	SendUserNickName string `gorm:"size:42"`
+	SendUserAvatar   string
+	RevUserID        uint   `gorm:"primaryKey"`
+	RevUserNickName  string `gorm:"size:42"`
+	RevUserAvatar    string
+	IsRead           bool `gorm:"default:false"`
+	Content          string
+}
+
+func (messageModelV1) TableName() string { return "message_models" }
+
+type advertModelV1 struct {
+	Model  model  `gorm:"embedded"`
+	Title  string `gorm:"size:32"`
+	Href   string
+	Images string
+	IsShow bool
+}
+
+func (advertModelV1) TableName() string { return "advert_models" }
+
+type userModelV1 struct {
+	Model      model  `gorm:"embedded"`
+	NickName   string `gorm:"size:36"`
+	UserName   string `gorm:"size:36"`
+	Password   string `gorm:"size:128"`
+	Avatar     string `gorm:"type=TEXT"`
+	Email      string `gorm:"size:128"`
+	Tel        string `gorm:"size:18"`
+	Addr       string `gorm:"size:64"`
+	Token      string `gorm:"size:64"`
+	IP         string `gorm:"size:20"`
+	Role       int    `gorm:"size:4;default:1"`
+	SignStatus int    `gorm:"type=smallint(6)"`
+	Integral   int    `gorm:"default:0"`
+	Sign       string `gorm:"size:128"`
+	Link       string `gorm:"size:128"`
+}
+
+func (userModelV1) TableName() string { return "user_models" }
+
+type commentModelV1 struct {
+	Model           model `gorm:"embedded"`
+	ParentCommentID *uint
+	Content         string `gorm:"size:256"`
+	DiggCount       int    `gorm:"size:8;default:0;"`
+	CommentCount    int    `gorm:"size:8;default:0;"`
+	ArticleID       string `gorm:"size:32"`
+	UserID          uint
+}
+
+func (commentModelV1) TableName() string { return "comment_models" }
+
+type userCollectModelV1 struct {
+	ID        uint `gorm:"primarykey"`
+	UserID    uint
+	ArticleID string `gorm:"size:32"`
+	CreatedAt time.Time
+}
+
+func (userCollectModelV1) TableName() string { return "user_collect_models" }
+
+type menuModelV1 struct {
+	Model        model  `gorm:"embedded"`
+	Title        string `gorm:"size:32"`
+	Path         string `gorm:"size:256"`
+	Slogan       string `gorm:"size:64"`
+	Abstract     string `gorm:"type:string"`
+	AbstractTime int
+	BannerTime   int
+	Sort         int `gorm:"size:10"`
+}
+
+func (menuModelV1) TableName() string { return "menu_models" }
+
+type menuBannerModelV1 struct {
+	MenuID   uint
+	BannerID uint
+	Sort     int `gorm:"size:10"`
+}
+
+func (menuBannerModelV1) TableName() string { return "menu_banner_models" }
+
+type fadeBackModelV1 struct {
+	Model        model  `gorm:"embedded"`
+	Email        string `gorm:"size:64"`
+	Content      string `gorm:"size:128"`
+	ApplyContent string `gorm:"size:128"`
+	IsApply      bool
+}
+
+func (fadeBackModelV1) TableName() string { return "fade_back_models" }
+
+type loginDataModelV1 struct {
+	Model     model `gorm:"embedded"`
+	UserID    uint
+	IP        string `gorm:"size:20"`
+	NickName  string `gorm:"size:42"`
+	Token     string `gorm:"size:256"`
+	Device    string `gorm:"size:256"`
+	Addr      string `gorm:"size:64"`
+	LoginType int    `gorm:"size:type=smallint(6)"`
+}
+
+func (loginDataModelV1) TableName() string { return "login_data_models" }
+
+type chatModelV1 struct {
+	Model    model  `gorm:"embedded"`
+	NickName string `gorm:"size:15"`
+	Avatar   string `gorm:"size:128"`
+	Content  string `gorm:"size:256"`
+	IP       string `gorm:"size:32"`
+	Addr     string `gorm:"size:64"`
+	ISGroup  bool
+	MsgType  int `gorm:"size:4"`
+}
+
+func (chatModelV1) TableName() string { return "chat_models" }
+
+type logStashModelV1 struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	IP        string `gorm:"size:32"`
+	Addr      string `gorm:"size:64"`
+	Level     int    `gorm:"size:4"`
+	Content   string `gorm:"size:128"`
+	UserID    uint
+}
+
+func (logStashModelV1) TableName() string { return "log_stash_models" }
@@ -0,0 +1,52 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// 文章存在mysql时的文章表和标签表，存在es时也建表，空表不影响使用，切换存储方式时不需要回滚
+var articleTables = Migration{
+	Version: 2,
+	Name:    "article_tables",
+	Up: func(tx *gorm.DB) error {
+		return createTables(tx, &articleModelV2{}, &articleTagModelV2{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, &articleTagModelV2{}, &articleModelV2{})
+	},
+}
+
+type articleModelV2 struct {
+	ID            string `gorm:"primaryKey;size:32"`
+	CreatedAt     string `gorm:"size:20;index"`
+	UpdatedAt     string `gorm:"size:20"`
+	Title         string `gorm:"size:128"`
+	Keyword       string `gorm:"size:128;index"`
+	Abstract      string
+	Content       string
+	LookCount     int
+	CommentCount  int
+	DiggCount     int
+	CollectsCount int
+	UserID        uint
+	UserNickName  string
+	UserAvatar    string
+	Category      string `gorm:"size:32"`
+	Source        string `gorm:"size:64"`
+	Link          string `gorm:"size:256"`
+	BannerID      uint
+	BannerUrl     string
+	Tags          string `gorm:"type:string"`
+	Status        int
+	PublishAt     string `gorm:"size:20"`
+}
+
+func (articleModelV2) TableName() string { return "article_models" }
+
+type articleTagModelV2 struct {
+	ID        uint   `gorm:"primarykey"`
+	ArticleID string `gorm:"size:32;index"`
+	Tag       string `gorm:"size:16;index"`
+}
+
+func (articleTagModelV2) TableName() string { return "article_tag_models" }
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// 文章修订记录，文章存在es时也存在数据库里
+var articleRevisionTables = Migration{
+	Version: 3,
+	Name:    "article_revision_tables",
+	Up: func(tx *gorm.DB) error {
+		return createTables(tx, &articleRevisionModelV3{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, &articleRevisionModelV3{})
+	},
+}
+
+type articleRevisionModelV3 struct {
+	Model        model  `gorm:"embedded"`
+	ArticleID    string `gorm:"size:32;index"`
+	Version      int
+	Title        string `gorm:"size:128"`
+	Abstract     string
+	Content      string
+	Category     string `gorm:"size:32"`
+	Tags         string `gorm:"type:string"`
+	BannerID     uint
+	UserID       uint
+	UserNickName string `gorm:"size:36"`
+	Summary      string `gorm:"size:256"`
+}
+
+func (articleRevisionModelV3) TableName() string { return "article_revision_models" }
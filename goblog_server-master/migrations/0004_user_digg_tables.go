@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 用户点赞记录
+var userDiggTables = Migration{
+	Version: 4,
+	Name:    "user_digg_tables",
+	Up: func(tx *gorm.DB) error {
+		return createTables(tx, &userDiggModelV4{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, &userDiggModelV4{})
+	},
+}
+
+type userDiggModelV4 struct {
+	ID        uint   `gorm:"primarykey"`
+	DiggType  string `gorm:"size:16;uniqueIndex:idx_user_digg"`
+	TargetID  string `gorm:"size:32;uniqueIndex:idx_user_digg"`
+	UserKey   string `gorm:"size:64;uniqueIndex:idx_user_digg"`
+	UserID    uint   `gorm:"index"`
+	CreatedAt time.Time
+}
+
+func (userDiggModelV4) TableName() string { return "user_digg_models" }
@@ -0,0 +1,95 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"gvb_server/service/comment_ser"
+)
+
+// 评论加上根评论id、物化路径和层级，按原来的父子关系回填
+var commentPath = Migration{
+	Version: 5,
+	Name:    "comment_path",
+	Up: func(tx *gorm.DB) error {
+		err := addColumns(tx, &commentModelV5{}, "RootID", "Path", "Depth")
+		if err != nil {
+			return err
+		}
+		err = createIndex(tx, &commentModelV5{}, "idx_comment_thread")
+		if err != nil {
+			return err
+		}
+		return backfillCommentPath(tx)
+	},
+	Down: func(tx *gorm.DB) error {
+		err := dropIndex(tx, &commentModelV5{}, "idx_comment_thread")
+		if err != nil {
+			return err
+		}
+		return dropColumns(tx, &commentModelV5{}, "RootID", "Path", "Depth")
+	},
+}
+
+// commentModelV5 这个版本评论表加的字段
+type commentModelV5 struct {
+	ID              uint `gorm:"primarykey"`
+	ParentCommentID *uint
+	RootID          uint   `gorm:"index:idx_comment_thread,priority:1"`
+	Path            string `gorm:"size:512;index:idx_comment_thread,priority:2"`
+	Depth           int    `gorm:"default:0"`
+}
+
+func (commentModelV5) TableName() string { return "comment_models" }
+
+// backfillCommentPath 回填已有评论的路径，父评论已经被删掉的当作根评论
+func backfillCommentPath(tx *gorm.DB) error {
+	type comment struct {
+		ID              uint
+		ParentCommentID *uint
+	}
+	var list []comment
+	err := tx.Model(&commentModelV5{}).Select("id", "parent_comment_id").Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return err
+	}
+	var parentMap = map[uint]*uint{}
+	for _, c := range list {
+		parentMap[c.ID] = c.ParentCommentID
+	}
+
+	type node struct {
+		RootID uint
+		Path   string
+		Depth  int
+	}
+	var nodeMap = map[uint]node{}
+	var resolve func(id uint, seen map[uint]bool) node
+	resolve = func(id uint, seen map[uint]bool) node {
+		if n, ok := nodeMap[id]; ok {
+			return n
+		}
+		n := node{RootID: id, Path: comment_ser.PathSegment(id)}
+		parentID, ok := parentMap[id]
+		// 有父评论并且没有成环
+		if ok && parentID != nil && !seen[*parentID] {
+			if _, has := parentMap[*parentID]; has {
+				seen[id] = true
+				parent := resolve(*parentID, seen)
+				n = node{RootID: parent.RootID, Path: parent.Path + comment_ser.PathSegment(id), Depth: parent.Depth + 1}
+			}
+		}
+		nodeMap[id] = n
+		return n
+	}
+	for _, c := range list {
+		n := resolve(c.ID, map[uint]bool{})
+		err = tx.Model(&commentModelV5{}).Where("id = ?", c.ID).Updates(map[string]any{
+			"root_id": n.RootID,
+			"path":    n.Path,
+			"depth":   n.Depth,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
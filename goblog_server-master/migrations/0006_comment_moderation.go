@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"gvb_server/models/ctype"
+)
+
+// 评论审核状态和用户信任等级，已有的评论都算已通过，已有的用户都算普通用户，升级后不会被当作新用户审核
+var commentModeration = Migration{
+	Version: 6,
+	Name:    "comment_moderation",
+	Up: func(tx *gorm.DB) error {
+		err := addColumns(tx, &commentModelV6{}, "Status", "Reason")
+		if err != nil {
+			return err
+		}
+		err = createIndex(tx, &commentModelV6{}, "Status")
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&commentModelV6{}).
+			Where("status = 0 or status is null").
+			Update("status", ctype.CommentApproved).Error
+		if err != nil {
+			return err
+		}
+		err = addColumns(tx, &userModelV6{}, "TrustLevel")
+		if err != nil {
+			return err
+		}
+		return tx.Model(&userModelV6{}).
+			Where("trust_level = ?", ctype.TrustNew).
+			Update("trust_level", ctype.TrustNormal).Error
+	},
+	Down: func(tx *gorm.DB) error {
+		err := dropColumns(tx, &userModelV6{}, "TrustLevel")
+		if err != nil {
+			return err
+		}
+		err = dropIndex(tx, &commentModelV6{}, "Status")
+		if err != nil {
+			return err
+		}
+		return dropColumns(tx, &commentModelV6{}, "Status", "Reason")
+	},
+}
+
+// commentModelV6 这个版本评论表加的字段
+type commentModelV6 struct {
+	ID     uint   `gorm:"primarykey"`
+	Status int    `gorm:"index"`
+	Reason string `gorm:"size:64"`
+}
+
+func (commentModelV6) TableName() string { return "comment_models" }
+
+// userModelV6 这个版本用户表加的字段
+type userModelV6 struct {
+	ID         uint `gorm:"primarykey"`
+	TrustLevel int  `gorm:"default:0"`
+}
+
+func (userModelV6) TableName() string { return "user_models" }
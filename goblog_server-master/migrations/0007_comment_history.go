@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 评论编辑历史和软删除
+var commentHistory = Migration{
+	Version: 7,
+	Name:    "comment_history",
+	Up: func(tx *gorm.DB) error {
+		err := addColumns(tx, &commentModelV7{}, "IsDeleted", "EditedAt")
+		if err != nil {
+			return err
+		}
+		return createTables(tx, &commentHistoryModelV7{})
+	},
+	Down: func(tx *gorm.DB) error {
+		err := dropTables(tx, &commentHistoryModelV7{})
+		if err != nil {
+			return err
+		}
+		return dropColumns(tx, &commentModelV7{}, "IsDeleted", "EditedAt")
+	},
+}
+
+// commentModelV7 这个版本评论表加的字段
+type commentModelV7 struct {
+	ID        uint `gorm:"primarykey"`
+	IsDeleted bool `gorm:"default:false"`
+	EditedAt  *time.Time
+}
+
+func (commentModelV7) TableName() string { return "comment_models" }
+
+type commentHistoryModelV7 struct {
+	Model     model  `gorm:"embedded"`
+	CommentID uint   `gorm:"index"`
+	Content   string `gorm:"size:256"`
+	Action    string `gorm:"size:16"`
+	UserID    uint
+}
+
+func (commentHistoryModelV7) TableName() string { return "comment_history_models" }
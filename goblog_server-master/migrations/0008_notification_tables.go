@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// 站内通知
+var notificationTables = Migration{
+	Version: 8,
+	Name:    "notification_tables",
+	Up: func(tx *gorm.DB) error {
+		return createTables(tx, &notificationModelV8{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, &notificationModelV8{})
+	},
+}
+
+type notificationModelV8 struct {
+	Model            model `gorm:"embedded"`
+	RevUserID        uint  `gorm:"index:idx_notification_user,priority:1"`
+	IsRead           bool  `gorm:"default:false;index:idx_notification_user,priority:2"`
+	Type             int
+	SendUserID       uint
+	SendUserNickName string `gorm:"size:42"`
+	SendUserAvatar   string
+	Title            string `gorm:"size:128"`
+	Content          string `gorm:"size:256"`
+	ArticleID        string `gorm:"size:32"`
+	CommentID        uint
+	IsEmailed        bool `gorm:"default:false"`
+}
+
+func (notificationModelV8) TableName() string { return "notification_models" }
@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// 聊天室，已有的群聊记录都在大厅（room_id为0）
+var chatRooms = Migration{
+	Version: 9,
+	Name:    "chat_rooms",
+	Up: func(tx *gorm.DB) error {
+		err := createTables(tx, &chatRoomModelV9{})
+		if err != nil {
+			return err
+		}
+		err = addColumns(tx, &chatModelV9{}, "RoomID", "UserID")
+		if err != nil {
+			return err
+		}
+		// 登录用户用真实昵称，长度和用户表一致
+		err = tx.Migrator().AlterColumn(&chatModelV9{}, "NickName")
+		if err != nil {
+			return err
+		}
+		return createIndex(tx, &chatModelV9{}, "RoomID")
+	},
+	// 昵称的长度不改回去，避免截断已有的记录
+	Down: func(tx *gorm.DB) error {
+		err := dropIndex(tx, &chatModelV9{}, "RoomID")
+		if err != nil {
+			return err
+		}
+		err = dropColumns(tx, &chatModelV9{}, "RoomID", "UserID")
+		if err != nil {
+			return err
+		}
+		return dropTables(tx, &chatRoomModelV9{})
+	},
+}
+
+type chatRoomModelV9 struct {
+	Model    model  `gorm:"embedded"`
+	Name     string `gorm:"size:32;uniqueIndex"`
+	Abstract string `gorm:"size:128"`
+	UserID   uint
+}
+
+func (chatRoomModelV9) TableName() string { return "chat_room_models" }
+
+// chatModelV9 这个版本群聊表加的字段和加长的昵称
+type chatModelV9 struct {
+	ID       uint   `gorm:"primarykey"`
+	NickName string `gorm:"size:36"`
+	RoomID   uint   `gorm:"index"`
+	UserID   uint
+}
+
+func (chatModelV9) TableName() string { return "chat_models" }
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// 修订记录的版本号加唯一索引，之前同时修改产生的重复版本号按id重新编号
+var articleRevisionVersion = Migration{
+	Version: 10,
+	Name:    "article_revision_version",
+	Up: func(tx *gorm.DB) error {
+		err := renumberRevisions(tx)
+		if err != nil {
+			return err
+		}
+		return createIndex(tx, &articleRevisionModelV10{}, "idx_article_version")
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropIndex(tx, &articleRevisionModelV10{}, "idx_article_version")
+	},
+}
+
+// renumberRevisions 有重复版本号的文章，所有修订记录按id从1开始重新编号
+func renumberRevisions(tx *gorm.DB) error {
+	var articleIDList []string
+	err := tx.Model(&articleRevisionModelV10{}).
+		Group("article_id, version").
+		Having("count(*) > 1").
+		Pluck("article_id", &articleIDList).Error
+	if err != nil {
+		return err
+	}
+	var renumbered = map[string]bool{}
+	for _, articleID := range articleIDList {
+		if renumbered[articleID] {
+			continue
+		}
+		renumbered[articleID] = true
+		var idList []uint
+		err = tx.Model(&articleRevisionModelV10{}).
+			Where("article_id = ?", articleID).
+			Order("id").
+			Pluck("id", &idList).Error
+		if err != nil {
+			return err
+		}
+		for i, id := range idList {
+			err = tx.Model(&articleRevisionModelV10{}).
+				Where("id = ?", id).
+				UpdateColumn("version", i+1).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// articleRevisionModelV10 这个版本修订记录加的唯一索引
+type articleRevisionModelV10 struct {
+	ID        uint   `gorm:"primarykey"`
+	ArticleID string `gorm:"size:32;uniqueIndex:idx_article_version"`
+	Version   int    `gorm:"uniqueIndex:idx_article_version"`
+}
+
+func (articleRevisionModelV10) TableName() string { return "article_revision_models" }
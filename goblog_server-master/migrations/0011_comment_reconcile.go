@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// 等待重新计算评论数的文章
+var commentReconcile = Migration{
+	Version: 11,
+	Name:    "comment_reconcile",
+	Up: func(tx *gorm.DB) error {
+		return createTables(tx, &commentReconcileModelV11{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return dropTables(tx, &commentReconcileModelV11{})
+	},
+}
+
+type commentReconcileModelV11 struct {
+	ArticleID string `gorm:"primaryKey;size:32"`
+	CreatedAt time.Time
+}
+
+func (commentReconcileModelV11) TableName() string { return "comment_reconcile_models" }
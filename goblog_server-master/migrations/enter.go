@@ -0,0 +1,232 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Migration 一次表结构变更，Version只增不改，已经上线的迁移不要修改，有变化就新增一个
+// 每个迁移用自己文件里的结构体快照建表和加字段，不引用models里的结构体，之后修改models不会影响已有的迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigrationModel 已执行的迁移记录
+type SchemaMigrationModel struct {
+	Version   int64     `gorm:"primarykey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"size:128" json:"name"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+func (SchemaMigrationModel) TableName() string {
+	return "schema_migrations"
+}
+
+// migrationList 所有的迁移，按版本号从小到大，新的迁移加在最后
+var migrationList = []Migration{
+	baseTables,
+	articleTables,
+	articleRevisionTables,
+	userDiggTables,
+	commentPath,
+	commentModeration,
+	commentHistory,
+	notificationTables,
+	chatRooms,
+	articleRevisionVersion,
+	commentReconcile,
+}
+
+// model 快照里的公共字段，对应 models.MODEL，gorm不解析非导出的匿名字段，快照里用 Model model `gorm:"embedded"`
+type model struct {
+	ID        uint `gorm:"primarykey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Status 迁移的执行状态
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time // 没有执行为nil
+}
+
+// appliedMap 已执行的版本 -> 执行记录
+func appliedMap(db *gorm.DB) (map[int64]SchemaMigrationModel, error) {
+	err := db.AutoMigrate(&SchemaMigrationModel{})
+	if err != nil {
+		return nil, err
+	}
+	var list []SchemaMigrationModel
+	err = db.Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	var applied = map[int64]SchemaMigrationModel{}
+	for _, model := range list {
+		applied[model.Version] = model
+	}
+	return applied, nil
+}
+
+// checkList 版本号必须递增，避免合并代码时撞号
+func checkList() error {
+	for i := 1; i < len(migrationList); i++ {
+		if migrationList[i].Version <= migrationList[i-1].Version {
+			return fmt.Errorf("迁移 %d_%s 的版本号必须大于 %d", migrationList[i].Version, migrationList[i].Name, migrationList[i-1].Version)
+		}
+	}
+	return nil
+}
+
+// Migrate 按顺序执行所有没有执行过的迁移，返回执行的迁移
+func Migrate(db *gorm.DB) (doneList []Migration, err error) {
+	err = checkList()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMap(db)
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range migrationList {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		// mysql的DDL会隐式提交，事务只对postgres、sqlite完全生效
+		err = db.Transaction(func(tx *gorm.DB) error {
+			err := migration.Up(tx)
+			if err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigrationModel{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return doneList, fmt.Errorf("执行迁移 %d_%s 失败 %w", migration.Version, migration.Name, err)
+		}
+		doneList = append(doneList, migration)
+	}
+	return doneList, nil
+}
+
+// Rollback 回滚最近执行的n个迁移，返回回滚的迁移
+func Rollback(db *gorm.DB, n int) (doneList []Migration, err error) {
+	if n <= 0 {
+		return nil, errors.New("回滚的数量必须大于0")
+	}
+	applied, err := appliedMap(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(migrationList) - 1; i >= 0 && len(doneList) < n; i-- {
+		migration := migrationList[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		err = db.Transaction(func(tx *gorm.DB) error {
+			err := migration.Down(tx)
+			if err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigrationModel{}, migration.Version).Error
+		})
+		if err != nil {
+			return doneList, fmt.Errorf("回滚迁移 %d_%s 失败 %w", migration.Version, migration.Name, err)
+		}
+		doneList = append(doneList, migration)
+	}
+	return doneList, nil
+}
+
+// StatusList 所有迁移的执行状态
+func StatusList(db *gorm.DB) ([]Status, error) {
+	applied, err := appliedMap(db)
+	if err != nil {
+		return nil, err
+	}
+	var list []Status
+	for _, migration := range migrationList {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if model, ok := applied[migration.Version]; ok {
+			appliedAt := model.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		list = append(list, status)
+	}
+	return list, nil
+}
+
+// createTables 建表，已存在的表会补上缺少的字段，兼容之前用AutoMigrate建好的库
+func createTables(tx *gorm.DB, modelList ...any) error {
+	if tx.Dialector.Name() == "mysql" {
+		tx = tx.Set("gorm:table_options", "ENGINE=InnoDB")
+	}
+	return tx.AutoMigrate(modelList...)
+}
+
+// dropTables 删表，不存在的跳过
+func dropTables(tx *gorm.DB, modelList ...any) error {
+	for _, model := range modelList {
+		if !tx.Migrator().HasTable(model) {
+			continue
+		}
+		err := tx.Migrator().DropTable(model)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addColumns 加字段，已存在的跳过
+func addColumns(tx *gorm.DB, model any, fieldList ...string) error {
+	for _, field := range fieldList {
+		if tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		err := tx.Migrator().AddColumn(model, field)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropColumns 删字段，不存在的跳过
+func dropColumns(tx *gorm.DB, model any, fieldList ...string) error {
+	for _, field := range fieldList {
+		if !tx.Migrator().HasColumn(model, field) {
+			continue
+		}
+		err := tx.Migrator().DropColumn(model, field)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createIndex 建索引，已存在的跳过
+func createIndex(tx *gorm.DB, model any, name string) error {
+	if tx.Migrator().HasIndex(model, name) {
+		return nil
+	}
+	return tx.Migrator().CreateIndex(model, name)
+}
+
+// dropIndex 删索引，不存在的跳过
+func dropIndex(tx *gorm.DB, model any, name string) error {
+	if !tx.Migrator().HasIndex(model, name) {
+		return nil
+	}
+	return tx.Migrator().DropIndex(model, name)
+}
@@ -0,0 +1,175 @@
+package migrations
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"gvb_server/internal/testdb"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/plugins/log_stash"
+	"testing"
+)
+
+func TestMigrateRollback(t *testing.T) {
+	db := testdb.Open(t)
+
+	doneList, err := Migrate(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doneList) != len(migrationList) {
+		t.Fatalf("migrated %d, want %d", len(doneList), len(migrationList))
+	}
+	if !db.Migrator().HasTable(&models.ArticleModel{}) || !db.Migrator().HasTable(&models.UserDiggModel{}) {
+		t.Fatal("tables not created")
+	}
+	// 再执行一次没有变化
+	doneList, err = Migrate(db)
+	if err != nil || len(doneList) != 0 {
+		t.Fatalf("second migrate: %d %v", len(doneList), err)
+	}
+
+	// 回滚到user_digg_tables之前
+	n := len(migrationList) - 3
+	doneList, err = Rollback(db, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doneList) != n || doneList[0].Version != migrationList[len(migrationList)-1].Version {
+		t.Fatalf("rollback: %+v", doneList)
+	}
+	if db.Migrator().HasTable(&models.UserDiggModel{}) {
+		t.Fatal("user_digg_models not dropped")
+	}
+	if db.Migrator().HasColumn(&models.CommentModel{}, "Path") {
+		t.Fatal("comment_models.path not dropped")
+	}
+
+	list, err := StatusList(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range list {
+		applied := status.AppliedAt != nil
+		if applied != (status.Version <= 3) {
+			t.Errorf("version %d applied=%v", status.Version, applied)
+		}
+	}
+}
+
+func TestBackfillCommentPath(t *testing.T) {
+	db := testdb.Open(t, &models.CommentModel{})
+	// 老数据只有父评论id，9的父评论已经被删掉
+	parent := func(id uint) *uint { return &id }
+	for _, c := range []models.CommentModel{
+		{MODEL: models.MODEL{ID: 1}},
+		{MODEL: models.MODEL{ID: 2}, ParentCommentID: parent(1)},
+		{MODEL: models.MODEL{ID: 3}, ParentCommentID: parent(2)},
+		{MODEL: models.MODEL{ID: 4}, ParentCommentID: parent(9)},
+	} {
+		db.Create(&c)
+	}
+	err := backfillCommentPath(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []models.CommentModel
+	db.Order("id").Find(&list)
+	want := []struct {
+		RootID uint
+		Path   string
+		Depth  int
+	}{
+		{1, "0000000001/", 0},
+		{1, "0000000001/0000000002/", 1},
+		{1, "0000000001/0000000002/0000000003/", 2},
+		{4, "0000000004/", 0},
+	}
+	for i, w := range want {
+		if list[i].RootID != w.RootID || list[i].Path != w.Path || list[i].Depth != w.Depth {
+			t.Errorf("comment %d: got %d %s %d, want %+v", list[i].ID, list[i].RootID, list[i].Path, list[i].Depth, w)
+		}
+	}
+}
+
+func TestBackfillTrustLevel(t *testing.T) {
+	db := testdb.Open(t)
+	_, err := Migrate(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 回滚到comment_moderation之前，插入一个老用户再升级
+	if _, err = Rollback(db, len(migrationList)-5); err != nil {
+		t.Fatal(err)
+	}
+	err = db.Exec("insert into user_models (nick_name, user_name) values (?, ?)", "old", "old").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	var user models.UserModel
+	db.Take(&user, "user_name = ?", "old")
+	if user.TrustLevel != ctype.TrustNormal {
+		t.Fatalf("trust level: %d", user.TrustLevel)
+	}
+}
+
+func TestRenumberRevisions(t *testing.T) {
+	db := testdb.Open(t)
+	err := db.Exec("create table article_revision_models (id integer primary key, article_id text, version integer)").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a1同时修改产生了两个版本2
+	err = db.Exec("insert into article_revision_models (id, article_id, version) values (1, 'a1', 1), (2, 'a1', 2), (3, 'a1', 2), (4, 'a2', 1)").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = articleRevisionVersion.Up(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versionList []int
+	db.Model(&models.ArticleRevisionModel{}).Order("id").Pluck("version", &versionList)
+	if fmt.Sprint(versionList) != "[1 2 3 1]" {
+		t.Fatalf("versions: %v", versionList)
+	}
+	err = db.Exec("insert into article_revision_models (id, article_id, version) values (5, 'a1', 3)").Error
+	if err == nil {
+		t.Fatal("duplicate version inserted")
+	}
+}
+
+// 迁移用的是快照，修改models后要新增迁移，否则这里会发现缺少的字段和索引
+func TestMigrationsMatchModels(t *testing.T) {
+	db := testdb.Open(t)
+	_, err := Migrate(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, model := range []any{
+		&models.BannerModel{}, &models.TagModel{}, &models.MessageModel{}, &models.AdvertModel{},
+		&models.UserModel{}, &models.CommentModel{}, &models.UserCollectModel{}, &models.MenuModel{},
+		&models.MenuBannerModel{}, &models.FadeBackModel{}, &models.LoginDataModel{}, &models.ChatModel{},
+		&log_stash.LogStashModel{}, &models.ArticleModel{}, &models.ArticleTagModel{},
+		&models.ArticleRevisionModel{}, &models.UserDiggModel{}, &models.CommentHistoryModel{},
+		&models.NotificationModel{}, &models.ChatRoomModel{}, &models.CommentReconcileModel{},
+	} {
+		stmt := &gorm.Statement{DB: db}
+		if err = stmt.Parse(model); err != nil {
+			t.Fatal(err)
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !db.Migrator().HasColumn(model, field.DBName) {
+				t.Errorf("%s.%s missing", stmt.Schema.Table, field.DBName)
+			}
+		}
+		for _, index := range stmt.Schema.ParseIndexes() {
+			if !db.Migrator().HasIndex(model, index.Name) {
+				t.Errorf("%s index %s missing", stmt.Schema.Table, index.Name)
+			}
+		}
+	}
+}
@@ -9,18 +9,19 @@ import (
 	"gvb_server/models/ctype"
 )
 
-// ArticleModel 文章表
+// ArticleModel 文章表，默认存在es中，gorm标签用于mysql存储
 type ArticleModel struct {
-	ID        string `json:"id" structs:"id"`
-	CreatedAt string `json:"created_at" structs:"created_at"`
-	UpdatedAt string `json:"updated_at" structs:"updated_at"`
+	ID        string `gorm:"primaryKey;size:32" json:"id" structs:"id"`
+	CreatedAt string `gorm:"size:20;index" json:"created_at" structs:"created_at"`
+	UpdatedAt string `gorm:"size:20" json:"updated_at" structs:"updated_at"`
 
-	Title    string `json:"title" structs:"title"`                // 文章标题
-	Keyword  string `json:"keyword,omit(list)" structs:"keyword"` // 关键字
-	Abstract string `json:"abstract" structs:"abstract"`          // 文章简介
-	Content  string `json:"content,omit(list)" structs:"content"` // 文章内容
+	Title    string `gorm:"size:128" json:"title" structs:"title"`                      // 文章标题
+	Keyword  string `gorm:"size:128;index" json:"keyword,omit(list)" structs:"keyword"` // 关键字
+	Abstract string `json:"abstract" structs:"abstract"`                                // 文章简介
+	Content  string `json:"content,omit(list)" structs:"content"`                       // 文章内容
 
 	LookCount     int `json:"look_count" structs:"look_count"`         // 浏览量
+	UvCount       int `gorm:"-" json:"uv_count" structs:"-"`           // 访客数，存在redis中，查询时填充
 	CommentCount  int `json:"comment_count" structs:"comment_count"`   // 评论量
 	DiggCount     int `json:"digg_count" structs:"digg_count"`         // 点赞量
 	CollectsCount int `json:"collects_count" structs:"collects_count"` // 收藏量
@@ -29,14 +30,17 @@ type ArticleModel struct {
 	UserNickName string `json:"user_nick_name" structs:"user_nick_name"` // 用户昵称
 	UserAvatar   string `json:"user_avatar" structs:"user_avatar"`       // 用户头像
 
-	Category string `json:"category" structs:"category"` // 文章分类
-	Source   string `json:"source" structs:"source"`     // 文章来源
-	Link     string `json:"link" structs:"link"`         // 原文链接
+	Category string `gorm:"size:32" json:"category" structs:"category"` // 文章分类
+	Source   string `gorm:"size:64" json:"source" structs:"source"`     // 文章来源
+	Link     string `gorm:"size:256" json:"link" structs:"link"`        // 原文链接
 
 	BannerID  uint   `json:"banner_id" structs:"banner_id"`   // 文章封面ID
 	BannerUrl string `json:"banner_url" structs:"banner_url"` // 文章封面
 
-	Tags ctype.Array `json:"tags" structs:"tags"` // 文章标签
+	Tags ctype.Array `gorm:"type:string" json:"tags" structs:"tags"` // 文章标签
+
+	Status    ctype.ArticleStatus `json:"status" structs:"status"`                                  // 文章状态
+	PublishAt string              `gorm:"size:20" json:"publish_at,omitempty" structs:"publish_at"` // 发布时间，待发布的文章到点后由定时任务发布，没有发布时间时es里不存这个字段
 }
 
 func (ArticleModel) Index() string {
@@ -104,6 +108,14 @@ func (ArticleModel) Mapping() string {
       "tags": { 
         "type": "keyword"
       },
+      "status": {
+        "type": "integer"
+      },
+      "publish_at":{
+        "type": "date",
+        "null_value": "null",
+        "format": "[yyyy-MM-dd HH:mm:ss]"
+      },
       "created_at":{
         "type": "date",
         "null_value": "null",
@@ -133,51 +145,24 @@ func (a ArticleModel) IndexExists() bool {
 	return exists
 }
 
-// CreateIndex 创建索引
+// CreateIndex 创建索引，实际创建的是 {index}_v1，再绑定别名；已存在时不会删除数据
 func (a ArticleModel) CreateIndex() error {
-	if a.IndexExists() {
-		// 有索引
-		err := a.RemoveIndex()
-		if err != nil {
-			return err
-		}
-	}
-	// 无索引
-	// 创建索引
-	createIndex, err := global.ESClient.
-		CreateIndex(a.Index()).
-		BodyString(a.Mapping()).
-		Do(context.Background())
-	if err != nil {
-		logrus.Error("创建索引失败")
-		logrus.Error(err.Error())
-		return err
-	}
-	if !createIndex.Acknowledged {
-		global.Log.Error("创建失败")
-		return err
-	}
-	logrus.Infof("索引 %s 创建成功", a.Index())
-	return nil
+	return createAliasIndex(a.Index(), a.Mapping())
+}
 
+// Reindex 按当前的mapping创建新版本的索引，迁移数据后切换别名，旧索引保留
+func (a ArticleModel) Reindex() error {
+	return reindexAlias(a.Index(), a.Mapping())
 }
 
-// RemoveIndex 删除索引
+// RollbackIndex 别名切回上一个版本的索引
+func (a ArticleModel) RollbackIndex() error {
+	return rollbackAlias(a.Index())
+}
+
+// RemoveIndex 删除索引，所有版本都会删掉
 func (a ArticleModel) RemoveIndex() error {
-	logrus.Info("索引存在，删除索引")
-	// 删除索引
-	indexDelete, err := global.ESClient.DeleteIndex(a.Index()).Do(context.Background())
-	if err != nil {
-		logrus.Error("删除索引失败")
-		logrus.Error(err.Error())
-		return err
-	}
-	if !indexDelete.Acknowledged {
-		logrus.Error("删除索引失败")
-		return err
-	}
-	logrus.Info("索引删除成功")
-	return nil
+	return removeAliasIndex(a.Index())
 }
 
 // Create 添加的方法
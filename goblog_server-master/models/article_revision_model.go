@@ -0,0 +1,19 @@
+package models
+
+import "gvb_server/models/ctype"
+
+// ArticleRevisionModel 文章修订记录，每次修改文章都会新增一条，不可修改
+type ArticleRevisionModel struct {
+	MODEL
+	ArticleID    string      `gorm:"size:32;index;uniqueIndex:idx_article_version" json:"article_id"` // 文章id
+	Version      int         `gorm:"uniqueIndex:idx_article_version" json:"version"`                  // 版本号，从1开始递增，同一篇文章不重复
+	Title        string      `gorm:"size:128" json:"title"`                                           // 文章标题
+	Abstract     string      `json:"abstract,omit(list)"`                                             // 文章简介
+	Content      string      `json:"content,omit(list)"`                                              // 文章内容
+	Category     string      `gorm:"size:32" json:"category"`                                         // 文章分类
+	Tags         ctype.Array `gorm:"type:string" json:"tags"`                                         // 文章标签
+	BannerID     uint        `json:"banner_id"`                                                       // 文章封面ID
+	UserID       uint        `json:"user_id"`                                                         // 修改人id
+	UserNickName string      `gorm:"size:36" json:"user_nick_name"`                                   // 修改人昵称
+	Summary      string      `gorm:"size:256" json:"summary"`                                         // 修改说明
+}
@@ -0,0 +1,8 @@
+package models
+
+// ArticleTagModel 文章和标签的关联，文章存在mysql时用于按标签分组
+type ArticleTagModel struct {
+	ID        uint   `gorm:"primarykey" json:"id"`
+	ArticleID string `gorm:"size:32;index" json:"article_id"`
+	Tag       string `gorm:"size:16;index" json:"tag"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// ChatRoomModel 聊天室，管理员创建，id为0的是默认的大厅，不存在表里
+type ChatRoomModel struct {
+	MODEL
+	Name     string `gorm:"size:32;uniqueIndex" json:"name"` // 聊天室名称
+	Abstract string `gorm:"size:128" json:"abstract"`        // 简介
+	UserID   uint   `json:"user_id"`                         // 创建人id
+}
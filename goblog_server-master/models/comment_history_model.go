@@ -0,0 +1,16 @@
+package models
+
+// 评论历史的操作类型
+const (
+	CommentHistoryEdit   = "edit"   // 编辑前的内容
+	CommentHistoryDelete = "delete" // 删除前的内容，恢复时用
+)
+
+// CommentHistoryModel 评论的历史版本，编辑和删除时保存之前的内容，不可修改
+type CommentHistoryModel struct {
+	MODEL
+	CommentID uint   `gorm:"index" json:"comment_id"` // 评论id
+	Content   string `gorm:"size:256" json:"content"` // 之前的内容
+	Action    string `gorm:"size:16" json:"action"`   // edit 编辑 delete 删除
+	UserID    uint   `json:"user_id"`                 // 操作人id
+}
@@ -1,15 +1,31 @@
 package models
 
+import (
+	"gvb_server/models/ctype"
+	"time"
+)
+
 // CommentModel 评论表
+// 每条评论存一份物化路径，根评论到自己每一级的id，一个线程的评论按路径排序就是树的先序遍历
 type CommentModel struct {
 	MODEL              `json:",select(c)"`
-	SubComments        []CommentModel `gorm:"foreignkey:ParentCommentID" json:"sub_comments,select(c)"` // 子评论列表
-	ParentCommentModel *CommentModel  `gorm:"foreignkey:ParentCommentID" json:"comment_model"`          // 父级评论
-	ParentCommentID    *uint          `json:"parent_comment_id,select(c)"`                              // 父评论id
-	Content            string         `gorm:"size:256" json:"content,select(c)"`                        // 评论内容
-	DiggCount          int            `gorm:"size:8;default:0;" json:"digg_count,select(c)"`            // 点赞数
-	CommentCount       int            `gorm:"size:8;default:0;" json:"comment_count,select(c)"`         // 子评论数
-	ArticleID          string         `gorm:"size:32" json:"article_id,select(c)"`                      // 文章id
-	User               UserModel      `json:"user,select(c)"`                                           //关联的用户
-	UserID             uint           `json:"user_id,select(c)"`                                        // 评论的用户
+	SubComments        []*CommentModel     `gorm:"foreignkey:ParentCommentID" json:"sub_comments,select(c)"`     // 子评论列表
+	ParentCommentModel *CommentModel       `gorm:"foreignkey:ParentCommentID" json:"comment_model"`              // 父级评论
+	ParentCommentID    *uint               `json:"parent_comment_id,select(c)"`                                  // 父评论id
+	RootID             uint                `gorm:"index:idx_comment_thread,priority:1" json:"root_id,select(c)"` // 根评论id，根评论为自己的id
+	Path               string              `gorm:"size:512;index:idx_comment_thread,priority:2" json:"-"`        // 物化路径，例如 0000000001/0000000005/
+	Depth              int                 `gorm:"default:0" json:"depth,select(c)"`                             // 层级，根评论为0
+	Content            string              `gorm:"size:256" json:"content,select(c)"`                            // 评论内容
+	DiggCount          int                 `gorm:"size:8;default:0;" json:"digg_count,select(c)"`                // 点赞数
+	CommentCount       int                 `gorm:"size:8;default:0;" json:"comment_count,select(c)"`             // 子评论数
+	ArticleID          string              `gorm:"size:32" json:"article_id,select(c)"`                          // 文章id
+	User               UserModel           `json:"user,select(c)"`                                               //关联的用户
+	UserID             uint                `json:"user_id,select(c)"`                                            // 评论的用户
+	Status             ctype.CommentStatus `gorm:"index" json:"status,select(c)"`                                // 审核状态
+	Reason             string              `gorm:"size:64" json:"reason"`                                        // 进入审核的原因
+	IsDeleted          bool                `gorm:"default:false" json:"is_deleted,select(c)"`                    // 是否已删除，删除后内容显示为[deleted]，回复保留
+	EditedAt           *time.Time          `json:"edited_at,select(c)"`                                          // 最后编辑时间
+	IsDigg             bool                `gorm:"-" json:"is_digg,select(c)"`                                   // 当前用户是否点赞
+	ReplyCursor        string              `gorm:"-" json:"reply_cursor,select(c)"`                              // 还有没加载的回复时，加载更多回复的游标
+	DisplayParentID    uint                `gorm:"-" json:"display_parent_id,select(c)"`                         // 顶层回复要挂到的评论，父评论没有通过审核时为根评论
 }
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// CommentReconcileModel 文章评论数写redis失败的文章，等待按数据库重新计算，存在数据库里重启后不会丢
+type CommentReconcileModel struct {
+	ArticleID string    `gorm:"primaryKey;size:32" json:"article_id"` // 文章id
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,60 @@
+package ctype
+
+// ArticleStatus 文章状态 草稿 -> 待发布 -> 已发布 -> 已归档
+// 存在es中的是数字，所以不实现MarshalJSON
+type ArticleStatus int
+
+const (
+	ArticleDraft     ArticleStatus = 1 // 草稿
+	ArticleReview    ArticleStatus = 2 // 审核中/待发布
+	ArticlePublished ArticleStatus = 3 // 已发布
+	ArticleArchived  ArticleStatus = 4 // 已归档
+)
+
+// IsPublished 是否对外可见，没有状态字段的老文章视为已发布
+func (s ArticleStatus) IsPublished() bool {
+	return s == ArticlePublished || s == 0
+}
+
+// IsValid 是否是合法的状态
+func (s ArticleStatus) IsValid() bool {
+	return s >= ArticleDraft && s <= ArticleArchived
+}
+
+// CanTransitTo 状态流转是否合法
+func (s ArticleStatus) CanTransitTo(target ArticleStatus) bool {
+	if s == 0 {
+		s = ArticlePublished
+	}
+	switch s {
+	case ArticleDraft:
+		return target == ArticleReview || target == ArticlePublished
+	case ArticleReview:
+		return target == ArticleDraft || target == ArticlePublished
+	case ArticlePublished:
+		return target == ArticleDraft || target == ArticleArchived
+	case ArticleArchived:
+		return target == ArticleDraft || target == ArticlePublished
+	}
+	return false
+}
+
+func (s ArticleStatus) String() string {
+	switch s {
+	case ArticleDraft:
+		return "草稿"
+	case ArticleReview:
+		return "待发布"
+	case ArticlePublished, 0:
+		return "已发布"
+	case ArticleArchived:
+		return "已归档"
+	default:
+		return "其他"
+	}
+}
+
+// HiddenArticleStatus 非管理员不可见的状态
+func HiddenArticleStatus() []any {
+	return []any{int(ArticleDraft), int(ArticleReview), int(ArticleArchived)}
+}
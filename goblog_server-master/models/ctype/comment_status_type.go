@@ -0,0 +1,37 @@
+package ctype
+
+import "encoding/json"
+
+// CommentStatus 评论审核状态
+type CommentStatus int
+
+const (
+	CommentPending  CommentStatus = 1 // 待审核
+	CommentApproved CommentStatus = 2 // 已通过
+	CommentRejected CommentStatus = 3 // 已拒绝
+	CommentSpam     CommentStatus = 4 // 垃圾评论
+)
+
+// IsValid 是否是合法的状态
+func (s CommentStatus) IsValid() bool {
+	return s >= CommentPending && s <= CommentSpam
+}
+
+func (s CommentStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s CommentStatus) String() string {
+	switch s {
+	case CommentPending:
+		return "待审核"
+	case CommentApproved:
+		return "已通过"
+	case CommentRejected:
+		return "已拒绝"
+	case CommentSpam:
+		return "垃圾评论"
+	default:
+		return "其他"
+	}
+}
@@ -1,3 +1,14 @@
 package ctype
 
+// MsgType 群聊消息类型
 type MsgType int
+
+const (
+	InRoomMsg  MsgType = 1 // 进入聊天室
+	TextMsg    MsgType = 2 // 发文本消息
+	ImageMsg   MsgType = 3 // 图片消息
+	VoiceMsg   MsgType = 4 // 语音消息
+	VideoMsg   MsgType = 5 // 视频消息
+	SystemMsg  MsgType = 6 // 系统消息
+	OutRoomMsg MsgType = 7 // 退出聊天室
+)
@@ -0,0 +1,43 @@
+package ctype
+
+import "encoding/json"
+
+// NotificationType 通知类型
+type NotificationType int
+
+const (
+	NotifyReply     NotificationType = 1 // 回复了我的评论
+	NotifyMention   NotificationType = 2 // @了我
+	NotifyLike      NotificationType = 3 // 赞了我的文章或评论
+	NotifyCollect   NotificationType = 4 // 收藏了我的文章
+	NotifySystem    NotificationType = 5 // 系统通知
+	NotifyBroadcast NotificationType = 6 // 管理员发给所有人的公告
+)
+
+// IsValid 是否是合法的类型
+func (t NotificationType) IsValid() bool {
+	return t >= NotifyReply && t <= NotifyBroadcast
+}
+
+func (t NotificationType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func (t NotificationType) String() string {
+	switch t {
+	case NotifyReply:
+		return "回复"
+	case NotifyMention:
+		return "提到"
+	case NotifyLike:
+		return "点赞"
+	case NotifyCollect:
+		return "收藏"
+	case NotifySystem:
+		return "系统通知"
+	case NotifyBroadcast:
+		return "公告"
+	default:
+		return "其他"
+	}
+}
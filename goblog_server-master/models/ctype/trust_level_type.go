@@ -0,0 +1,15 @@
+package ctype
+
+// TrustLevel 用户的信任等级，决定评论是否需要审核
+type TrustLevel int
+
+const (
+	TrustNew     TrustLevel = 0 // 新用户，评论都要审核
+	TrustNormal  TrustLevel = 1 // 普通用户，命中敏感词才要审核
+	TrustTrusted TrustLevel = 2 // 信任用户，不经过审核，只检查垃圾评论
+)
+
+// IsValid 是否是合法的等级
+func (l TrustLevel) IsValid() bool {
+	return l >= TrustNew && l <= TrustTrusted
+}
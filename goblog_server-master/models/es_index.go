@@ -0,0 +1,307 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/olivere/elastic/v7"
+	"github.com/sirupsen/logrus"
+	"gvb_server/global"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 业务代码只使用别名（article_index），别名指向带版本号的物理索引（article_index_v1、article_index_v2...）
+// 修改mapping时新建下一个版本的索引，把数据reindex过去，再原子地切换别名，旧索引保留用于回滚
+
+// versionIndex 别名对应的某个版本的物理索引
+func versionIndex(alias string, version int) string {
+	return fmt.Sprintf("%s_v%d", alias, version)
+}
+
+// indexVersionList 别名下已有的版本号，从小到大
+func indexVersionList(alias string) ([]int, error) {
+	nameList, err := global.ESClient.IndexNames()
+	if err != nil {
+		return nil, err
+	}
+	var versionList []int
+	for _, name := range nameList {
+		if !strings.HasPrefix(name, alias+"_v") {
+			continue
+		}
+		version, err := strconv.Atoi(strings.TrimPrefix(name, alias+"_v"))
+		if err != nil {
+			continue
+		}
+		versionList = append(versionList, version)
+	}
+	sort.Ints(versionList)
+	return versionList, nil
+}
+
+// aliasIndex 别名当前指向的物理索引
+// 老版本直接用别名作为索引名，这种情况返回别名本身，版本号为0
+func aliasIndex(alias string) (index string, version int, err error) {
+	exists, err := global.ESClient.IndexExists(alias).Do(context.Background())
+	if err != nil {
+		return "", 0, err
+	}
+	if !exists {
+		return "", 0, fmt.Errorf("索引 %s 不存在", alias)
+	}
+	result, err := global.ESClient.Aliases().Alias(alias).Do(context.Background())
+	if err != nil && !elastic.IsNotFound(err) {
+		return "", 0, err
+	}
+	if result != nil {
+		indexList := result.IndicesByAlias(alias)
+		if len(indexList) > 0 {
+			index = indexList[0]
+			version, _ = strconv.Atoi(strings.TrimPrefix(index, alias+"_v"))
+			return index, version, nil
+		}
+	}
+	return alias, 0, nil
+}
+
+// createVersionIndex 创建指定版本的物理索引
+func createVersionIndex(index, mapping string) error {
+	createIndex, err := global.ESClient.
+		CreateIndex(index).
+		BodyString(mapping).
+		Do(context.Background())
+	if err != nil {
+		logrus.Errorf("创建索引 %s 失败 %s", index, err)
+		return err
+	}
+	if !createIndex.Acknowledged {
+		return fmt.Errorf("创建索引 %s 未确认", index)
+	}
+	logrus.Infof("索引 %s 创建成功", index)
+	return nil
+}
+
+// createAliasIndex 创建第一个版本的索引并绑定别名，已经存在则跳过，不会删除数据
+func createAliasIndex(alias, mapping string) error {
+	exists, err := global.ESClient.IndexExists(alias).Do(context.Background())
+	if err != nil {
+		return err
+	}
+	if exists {
+		logrus.Infof("索引 %s 已存在，修改mapping请使用 -es reindex", alias)
+		return nil
+	}
+
+	index, err := nextVersionIndex(alias)
+	if err != nil {
+		return err
+	}
+	err = createVersionIndex(index, mapping)
+	if err != nil {
+		return err
+	}
+	_, err = global.ESClient.Alias().Add(index, alias).Do(context.Background())
+	if err != nil {
+		logrus.Errorf("绑定别名 %s 失败 %s", alias, err)
+		return err
+	}
+	logrus.Infof("别名 %s -> %s", alias, index)
+	return nil
+}
+
+// setWriteBlock 设置索引是否只读
+func setWriteBlock(index string, block bool) error {
+	_, err := global.ESClient.IndexPutSettings(index).
+		BodyJson(map[string]any{"index.blocks.write": block}).
+		Do(context.Background())
+	if err != nil {
+		logrus.Errorf("设置索引 %s 只读 %v 失败 %s", index, block, err)
+	}
+	return err
+}
+
+// copyIndex 复制索引数据，调用前源索引需要只读，否则复制期间的写入会丢
+func copyIndex(source, dest string) error {
+	result, err := global.ESClient.Reindex().
+		SourceIndex(source).
+		DestinationIndex(dest).
+		WaitForCompletion(true).
+		Refresh("true").
+		Do(context.Background())
+	if err != nil {
+		logrus.Errorf("迁移数据 %s -> %s 失败 %s", source, dest, err)
+		return err
+	}
+	logrus.Infof("迁移数据 %s -> %s 共 %d 条", source, dest, result.Total)
+	return nil
+}
+
+// nextVersionIndex 别名下一个版本的物理索引
+func nextVersionIndex(alias string) (string, error) {
+	versionList, err := indexVersionList(alias)
+	if err != nil {
+		return "", err
+	}
+	version := 1
+	if len(versionList) > 0 {
+		version = versionList[len(versionList)-1] + 1
+	}
+	return versionIndex(alias, version), nil
+}
+
+// copyLegacyIndex 老版本直接用别名作为索引名，按它原来的mapping复制一份带版本号的索引，再把别名切过去
+// 同名索引只能在切换别名的同一个请求里删掉，所以要先复制，保证数据不丢，之后就能按正常流程迁移和回滚
+func copyLegacyIndex(alias string) (index string, err error) {
+	mappingResult, err := global.ESClient.GetMapping().Index(alias).Do(context.Background())
+	if err != nil {
+		return "", err
+	}
+	legacy, ok := mappingResult[alias].(map[string]any)
+	if !ok {
+		return "", fmt.Errorf("索引 %s 的mapping读取失败", alias)
+	}
+	body, err := json.Marshal(map[string]any{"mappings": legacy["mappings"]})
+	if err != nil {
+		return "", err
+	}
+	index, err = nextVersionIndex(alias)
+	if err != nil {
+		return "", err
+	}
+	err = createVersionIndex(index, string(body))
+	if err != nil {
+		return "", err
+	}
+
+	err = setWriteBlock(alias, true)
+	if err != nil {
+		return "", err
+	}
+	err = copyIndex(alias, index)
+	if err == nil {
+		_, err = global.ESClient.Alias().
+			Action(elastic.NewAliasAddAction(alias).Index(index)).
+			Action(elastic.NewAliasRemoveIndexAction(alias)).
+			Do(context.Background())
+	}
+	if err != nil {
+		logrus.Errorf("复制旧索引 %s 失败 %s", alias, err)
+		// 旧索引还在，恢复写入
+		setWriteBlock(alias, false)
+		return "", err
+	}
+	logrus.Infof("旧索引 %s 已复制到 %s，别名 %s -> %s", alias, index, alias, index)
+	return index, nil
+}
+
+// reindexAlias 用新的mapping建下一个版本的索引，迁移数据后切换别名
+// 迁移期间旧索引只读，读请求不受影响，写请求会失败，避免复制过程中的更新和删除丢失
+func reindexAlias(alias, mapping string) error {
+	oldIndex, _, err := aliasIndex(alias)
+	if err != nil {
+		return err
+	}
+	if oldIndex == alias {
+		oldIndex, err = copyLegacyIndex(alias)
+		if err != nil {
+			return err
+		}
+	}
+	newIndex, err := nextVersionIndex(alias)
+	if err != nil {
+		return err
+	}
+	err = createVersionIndex(newIndex, mapping)
+	if err != nil {
+		return err
+	}
+
+	err = setWriteBlock(oldIndex, true)
+	if err != nil {
+		return err
+	}
+	// 切换别名后恢复旧索引的写入，回滚后还能正常使用
+	defer setWriteBlock(oldIndex, false)
+
+	err = copyIndex(oldIndex, newIndex)
+	if err != nil {
+		return err
+	}
+
+	// 原子切换别名
+	_, err = global.ESClient.Alias().
+		Remove(oldIndex, alias).
+		Add(newIndex, alias).
+		Do(context.Background())
+	if err != nil {
+		logrus.Errorf("切换别名 %s 失败 %s", alias, err)
+		return err
+	}
+	logrus.Infof("别名 %s -> %s，旧索引 %s 已保留，可用 -es rollback 回滚", alias, newIndex, oldIndex)
+	return nil
+}
+
+// rollbackAlias 别名切回上一个版本的索引
+func rollbackAlias(alias string) error {
+	index, version, err := aliasIndex(alias)
+	if err != nil {
+		return err
+	}
+	versionList, err := indexVersionList(alias)
+	if err != nil {
+		return err
+	}
+	var prevVersion int
+	for _, v := range versionList {
+		if v < version {
+			prevVersion = v
+		}
+	}
+	if prevVersion == 0 {
+		return fmt.Errorf("索引 %s 没有可以回滚的版本", index)
+	}
+	prevIndex := versionIndex(alias, prevVersion)
+	_, err = global.ESClient.Alias().
+		Remove(index, alias).
+		Add(prevIndex, alias).
+		Do(context.Background())
+	if err != nil {
+		logrus.Errorf("切换别名 %s 失败 %s", alias, err)
+		return err
+	}
+	logrus.Infof("别名 %s -> %s，回滚前的索引 %s 已保留", alias, prevIndex, index)
+	return nil
+}
+
+// removeAliasIndex 删除别名下所有版本的索引
+func removeAliasIndex(alias string) error {
+	versionList, err := indexVersionList(alias)
+	if err != nil {
+		return err
+	}
+	var indexList []string
+	for _, version := range versionList {
+		indexList = append(indexList, versionIndex(alias, version))
+	}
+	// 老版本的索引名就是别名
+	index, _, err := aliasIndex(alias)
+	if err == nil && index == alias {
+		indexList = append(indexList, alias)
+	}
+	if len(indexList) == 0 {
+		return nil
+	}
+	indexDelete, err := global.ESClient.DeleteIndex(indexList...).Do(context.Background())
+	if err != nil {
+		logrus.Error("删除索引失败")
+		logrus.Error(err.Error())
+		return err
+	}
+	if !indexDelete.Acknowledged {
+		return fmt.Errorf("删除索引 %s 未确认", alias)
+	}
+	logrus.Infof("索引 %s 删除成功", strings.Join(indexList, ","))
+	return nil
+}
@@ -59,49 +59,22 @@ func (a FullTextModel) IndexExists() bool {
 	return exists
 }
 
-// CreateIndex 创建索引
+// CreateIndex 创建索引，实际创建的是 {index}_v1，再绑定别名；已存在时不会删除数据
 func (a FullTextModel) CreateIndex() error {
-	if a.IndexExists() {
-		// 有索引
-		err := a.RemoveIndex()
-		if err != nil {
-			return err
-		}
-	}
-	// 无索引
-	// 创建索引
-	createIndex, err := global.ESClient.
-		CreateIndex(a.Index()).
-		BodyString(a.Mapping()).
-		Do(context.Background())
-	if err != nil {
-		logrus.Error("创建索引失败")
-		logrus.Error(err.Error())
-		return err
-	}
-	if !createIndex.Acknowledged {
-		global.Log.Error("创建失败")
-		return err
-	}
-	logrus.Infof("索引 %s 创建成功", a.Index())
-	return nil
+	return createAliasIndex(a.Index(), a.Mapping())
+}
 
+// Reindex 按当前的mapping创建新版本的索引，迁移数据后切换别名，旧索引保留
+func (a FullTextModel) Reindex() error {
+	return reindexAlias(a.Index(), a.Mapping())
 }
 
-// RemoveIndex 删除索引
+// RollbackIndex 别名切回上一个版本的索引
+func (a FullTextModel) RollbackIndex() error {
+	return rollbackAlias(a.Index())
+}
+
+// RemoveIndex 删除索引，所有版本都会删掉
 func (a FullTextModel) RemoveIndex() error {
-	logrus.Info("索引存在，删除索引")
-	// 删除索引
-	indexDelete, err := global.ESClient.DeleteIndex(a.Index()).Do(context.Background())
-	if err != nil {
-		logrus.Error("删除索引失败")
-		logrus.Error(err.Error())
-		return err
-	}
-	if !indexDelete.Acknowledged {
-		logrus.Error("删除索引失败")
-		return err
-	}
-	logrus.Info("索引删除成功")
-	return nil
+	return removeAliasIndex(a.Index())
 }
@@ -0,0 +1,19 @@
+package models
+
+import "gvb_server/models/ctype"
+
+// NotificationModel 站内通知，每个接收人一条
+type NotificationModel struct {
+	MODEL
+	RevUserID        uint                   `gorm:"index:idx_notification_user,priority:1" json:"rev_user_id"`           // 接收人id
+	IsRead           bool                   `gorm:"default:false;index:idx_notification_user,priority:2" json:"is_read"` // 是否已读
+	Type             ctype.NotificationType `json:"type"`                                                                // 通知类型
+	SendUserID       uint                   `json:"send_user_id"`                                                        // 触发通知的用户id，系统通知为0
+	SendUserNickName string                 `gorm:"size:42" json:"send_user_nick_name"`
+	SendUserAvatar   string                 `json:"send_user_avatar"`
+	Title            string                 `gorm:"size:128" json:"title"`     // 标题
+	Content          string                 `gorm:"size:256" json:"content"`   // 内容摘要
+	ArticleID        string                 `gorm:"size:32" json:"article_id"` // 相关的文章id
+	CommentID        uint                   `json:"comment_id"`                // 相关的评论id
+	IsEmailed        bool                   `gorm:"default:false" json:"-"`    // 是否已经发过邮件摘要
+}
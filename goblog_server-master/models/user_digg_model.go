@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// UserDiggModel 用户点赞记录，同一个用户对同一个文章或评论只能有一条
+// 未登录的用户按ip和ua生成的指纹区分
+type UserDiggModel struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	DiggType  string    `gorm:"size:16;uniqueIndex:idx_user_digg" json:"digg_type"` // article 文章 comment 评论
+	TargetID  string    `gorm:"size:32;uniqueIndex:idx_user_digg" json:"target_id"` // 文章id或评论id
+	UserKey   string    `gorm:"size:64;uniqueIndex:idx_user_digg" json:"-"`         // user:用户id 或 anon:指纹
+	UserID    uint      `gorm:"index" json:"user_id"`                               // 未登录为0
+	CreatedAt time.Time `json:"created_at"`
+}
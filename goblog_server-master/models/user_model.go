@@ -19,4 +19,5 @@ type UserModel struct {
 	Integral   int              `gorm:"default:0" json:"integral,select(info)"`           // 积分
 	Sign       string           `gorm:"size:128" json:"sign,select(info)"`                // 签名
 	Link       string           `gorm:"size:128" json:"link,select(info)"`                // 链接地址
+	TrustLevel ctype.TrustLevel `gorm:"default:0" json:"trust_level,select(info)"`        // 信任等级，评论审核用
 }
@@ -8,9 +8,10 @@ import (
 type Subject string
 
 const (
-	Code  Subject = "平台验证码"
-	Note  Subject = "操作通知"
-	Alarm Subject = "告警通知"
+	Code   Subject = "平台验证码"
+	Note   Subject = "操作通知"
+	Alarm  Subject = "告警通知"
+	Digest Subject = "未读通知摘要"
 )
 
 type Api struct {
@@ -36,6 +37,11 @@ func NewAlarm() Api {
 		Subject: Alarm,
 	}
 }
+func NewDigest() Api {
+	return Api{
+		Subject: Digest,
+	}
+}
 
 // send 邮件发送  发给谁，主题，正文
 func send(name, subject, body string) error {
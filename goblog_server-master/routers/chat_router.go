@@ -2,10 +2,14 @@ package routers
 
 import (
 	"gvb_server/api"
+	"gvb_server/middleware"
 )
 
 func (router RouterGroup) ChatRouter() {
 	app := api.ApiGroupApp.ChatApi
 	router.GET("chat_groups", app.ChatGroupView)
 	router.GET("chat_groups_records", app.ChatListView)
+	router.GET("chat_rooms", app.ChatRoomListView)
+	router.POST("chat_rooms", middleware.JwtAdmin(), app.ChatRoomCreateView)
+	router.DELETE("chat_rooms", middleware.JwtAdmin(), app.ChatRoomRemoveView)
 }
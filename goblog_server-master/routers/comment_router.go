@@ -9,7 +9,13 @@ func (router RouterGroup) CommentRouter() {
 	app := api.ApiGroupApp.CommentApi
 	router.POST("comments", middleware.JwtAuth(), app.CommentCreateView)
 	router.GET("comments_all", app.CommentListAllView)
+	router.GET("comments/replies", app.CommentReplyListView)
+	router.GET("comments/moderation", middleware.JwtAdmin(), app.CommentModerationListView)
+	router.PUT("comments/moderation", middleware.JwtAdmin(), app.CommentModerationUpdateView)
+	router.GET("comments/history/:id", middleware.JwtAuth(), app.CommentHistoryListView)
+	router.POST("comments/restore/:id", middleware.JwtAdmin(), app.CommentRestoreView)
 	router.POST("comments/:id", app.CommentDigg)
+	router.PUT("comments/:id", middleware.JwtAuth(), app.CommentUpdateView)
 	router.DELETE("comments/:id", middleware.JwtAuth(), app.CommentRemoveView)
 	router.GET("comments/:id", app.CommentListView)
 }
@@ -1,10 +1,16 @@
 package routers
 
-import "gvb_server/api"
+import (
+	"gvb_server/api"
+	"gvb_server/middleware"
+)
 
 func (router RouterGroup) DataRouter() {
 	app := api.ApiGroupApp.DataApi
 	router.GET("data_seven_login", app.DataSevenLogin)
 	router.GET("data_sum", app.DataSumView)
+	router.GET("data_stats", middleware.JwtAdmin(), app.DataStatsView)                      // 数据统计
+	router.GET("data_article_views", middleware.JwtAdmin(), app.DataArticleViewsView)       // 文章每日浏览量
+	router.GET("data_article_analysis", middleware.JwtAdmin(), app.DataArticleAnalysisView) // 文章数据分析
 
 }
@@ -19,6 +19,10 @@ func InitRouter() *gin.Engine {
 	// 静态文件路径，静态路由
 	router.StaticFS("uploads", http.Dir("uploads"))
 	router.GET("/swagger/*any", gs.WrapHandler(swaggerFiles.Handler))
+	// 订阅和服务端渲染的页面
+	rootRouterGroup := RouterGroup{&router.RouterGroup}
+	rootRouterGroup.FeedRouter()
+	rootRouterGroup.PageRouter()
 	apiRouterGroup := router.Group("api")
 
 	routerGroupApp := RouterGroup{apiRouterGroup}
@@ -36,5 +40,6 @@ func InitRouter() *gin.Engine {
 	routerGroupApp.ChatRouter()
 	routerGroupApp.LogRouter()
 	routerGroupApp.DataRouter()
+	routerGroupApp.NotificationRouter()
 	return router
 }
@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"gvb_server/api"
+)
+
+// FeedRouter 订阅、sitemap挂在根路径下，不在/api里
+func (router RouterGroup) FeedRouter() {
+	app := api.ApiGroupApp.FeedApi
+	router.GET("feed.xml", app.FeedRSSView)                      // rss
+	router.GET("atom.xml", app.FeedAtomView)                     // atom
+	router.GET("feed.json", app.FeedJSONView)                    // json feed
+	router.GET("tag/:tag/feed.xml", app.FeedRSSView)             // 标签rss
+	router.GET("tag/:tag/atom.xml", app.FeedAtomView)            // 标签atom
+	router.GET("tag/:tag/feed.json", app.FeedJSONView)           // 标签json feed
+	router.GET("category/:category/feed.xml", app.FeedRSSView)   // 分类rss
+	router.GET("category/:category/atom.xml", app.FeedAtomView)  // 分类atom
+	router.GET("category/:category/feed.json", app.FeedJSONView) // 分类json feed
+	router.GET("sitemap.xml", app.SitemapView)                   // sitemap
+	router.GET("sitemaps/:page", app.SitemapPageView)            // 拆分后的sitemap
+	router.GET("robots.txt", app.RobotsView)                     // robots.txt
+}
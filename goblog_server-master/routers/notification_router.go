@@ -0,0 +1,15 @@
+package routers
+
+import (
+	"gvb_server/api"
+	"gvb_server/middleware"
+)
+
+func (router RouterGroup) NotificationRouter() {
+	app := api.ApiGroupApp.NotificationApi
+	router.GET("notifications", middleware.JwtAuth(), app.NotificationListView)
+	router.GET("notifications/unread", middleware.JwtAuth(), app.NotificationUnreadView)
+	router.PUT("notifications/read", middleware.JwtAuth(), app.NotificationReadView)
+	router.GET("notifications/stream", middleware.JwtStream(), app.NotificationStreamView) // 实时推送
+	router.POST("notifications/broadcast", middleware.JwtAdmin(), app.NotificationBroadcastView)
+}
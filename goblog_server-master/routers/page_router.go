@@ -0,0 +1,11 @@
+package routers
+
+import (
+	"gvb_server/api"
+)
+
+// PageRouter 服务端渲染的页面，挂在根路径下
+func (router RouterGroup) PageRouter() {
+	app := api.ApiGroupApp.ArticleApi
+	router.GET("article/:id", app.ArticlePageView) // 文章分享页
+}
@@ -18,6 +18,7 @@ func (router RouterGroup) UserRouter() {
 	router.POST("users", middleware.JwtAdmin(), app.UserCreateView)
 	router.GET("users", middleware.JwtAuth(), app.UserListView)
 	router.PUT("user_role", middleware.JwtAdmin(), app.UserUpdateRoleView)
+	router.PUT("user_trust_level", middleware.JwtAdmin(), app.UserUpdateTrustLevelView)
 	router.PUT("user_password", middleware.JwtAuth(), app.UserUpdatePassword)
 	router.POST("logout", middleware.JwtAuth(), app.LogoutView)
 	router.DELETE("users", middleware.JwtAdmin(), app.UserRemove)
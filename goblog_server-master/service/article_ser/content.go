@@ -0,0 +1,43 @@
+package article_ser
+
+import (
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/PuerkitoBio/goquery"
+	"github.com/russross/blackfriday"
+	"strings"
+)
+
+// FilterXSS 正文转为html后去掉script标签，返回过滤后的markdown和正文的纯文本
+func (ArticleService) FilterXSS(content string) (safe string, text string, filtered bool) {
+	unsafe := blackfriday.MarkdownCommon([]byte(content))
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(string(unsafe)))
+	safe = content
+	if len(doc.Find("script").Nodes) > 0 {
+		// 有script标签
+		doc.Find("script").Remove()
+		converter := md.NewConverter("", true, nil)
+		html, _ := doc.Html()
+		safe, _ = converter.ConvertString(html)
+		filtered = true
+	}
+	return safe, doc.Text(), filtered
+}
+
+// GetAbstract 截取纯文本的前100个字作为简介
+func (ArticleService) GetAbstract(text string) string {
+	// 汉字的截取不一样
+	abs := []rune(text)
+	if len(abs) > 100 {
+		return string(abs[:100])
+	}
+	return string(abs)
+}
+
+// RenderHTML 正文渲染为html，去掉script标签
+func (ArticleService) RenderHTML(content string) string {
+	unsafe := blackfriday.MarkdownCommon([]byte(content))
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(string(unsafe)))
+	doc.Find("script").Remove()
+	html, _ := doc.Find("body").Html()
+	return html
+}
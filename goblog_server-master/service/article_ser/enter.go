@@ -0,0 +1,4 @@
+package article_ser
+
+type ArticleService struct {
+}
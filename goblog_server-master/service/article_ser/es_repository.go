@@ -0,0 +1,401 @@
+package article_ser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/olivere/elastic/v7"
+	"github.com/sirupsen/logrus"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"time"
+)
+
+// EsRepository 文章存在es的article_index
+type EsRepository struct {
+}
+
+// FilterPublished 过滤掉未发布的文章，没有status字段的老文章视为已发布
+func FilterPublished(query *elastic.BoolQuery) *elastic.BoolQuery {
+	return query.MustNot(elastic.NewTermsQuery("status", ctype.HiddenArticleStatus()...))
+}
+
+func (EsRepository) Create(article *models.ArticleModel) error {
+	return article.Create()
+}
+
+func (EsRepository) Detail(id string) (model models.ArticleModel, err error) {
+	res, err := global.ESClient.
+		Get().
+		Index(models.ArticleModel{}.Index()).
+		Id(id).
+		Do(context.Background())
+	if err != nil {
+		logrus.Error(err.Error())
+		return
+	}
+	err = json.Unmarshal(res.Source, &model)
+	if err != nil {
+		return
+	}
+	model.ID = res.Id
+	return
+}
+
+func (EsRepository) DetailByTitle(title string) (model models.ArticleModel, err error) {
+	res, err := global.ESClient.Search().
+		Index(models.ArticleModel{}.Index()).
+		Query(elastic.NewTermQuery("keyword", title)).
+		Size(1).
+		Do(context.Background())
+	if err != nil {
+		logrus.Error(err.Error())
+		return
+	}
+	if res.Hits.TotalHits.Value == 0 {
+		return model, errors.New("文章不存在")
+	}
+	hit := res.Hits.Hits[0]
+	err = json.Unmarshal(hit.Source, &model)
+	if err != nil {
+		return
+	}
+	model.ID = hit.Id
+	return
+}
+
+func (EsRepository) IsExistTitle(title string) bool {
+	return models.ArticleModel{Title: title}.ISExistData()
+}
+
+func (EsRepository) List(option ListOption) (list []models.ArticleModel, count int, err error) {
+	query := elastic.NewBoolQuery()
+	if option.Key != "" {
+		query.Must(
+			elastic.NewMultiMatchQuery(option.Key, option.Fields...),
+		)
+	}
+	// 根据标签搜
+	if option.Tag != "" {
+		query.Must(
+			elastic.NewMultiMatchQuery(option.Tag, "tags"),
+		)
+	}
+	if option.Category != "" {
+		query.Must(elastic.NewTermQuery("category", option.Category))
+	}
+	if option.UserID != 0 {
+		query.Must(elastic.NewTermQuery("user_id", option.UserID))
+	}
+	if option.Status != 0 {
+		query.Must(elastic.NewTermQuery("status", int(option.Status)))
+	}
+	// 非管理员只能看到已发布的文章
+	if !option.ShowHidden {
+		FilterPublished(query)
+	}
+	field, ascending := option.sortField()
+
+	res, err := global.ESClient.
+		Search(models.ArticleModel{}.Index()).
+		Query(query).
+		Highlight(elastic.NewHighlight().Field("title")).
+		From(option.GetForm()).
+		Sort(field, ascending).
+		Size(option.Limit).
+		Do(context.Background())
+	if err != nil {
+		logrus.Error(err.Error())
+		return
+	}
+
+	count = int(res.Hits.TotalHits.Value) //搜索到的结果总条数
+	list = []models.ArticleModel{}
+	for _, hit := range res.Hits.Hits {
+		var model models.ArticleModel
+		err = json.Unmarshal(hit.Source, &model)
+		if err != nil {
+			logrus.Error(err)
+			continue
+		}
+		title, ok := hit.Highlight["title"]
+		if ok {
+			model.Title = title[0]
+		}
+		model.ID = hit.Id
+		list = append(list, model)
+	}
+	return list, count, nil
+}
+
+func (r EsRepository) ListByIDList(idList []string) ([]models.ArticleModel, error) {
+	var _idList []interface{}
+	for _, id := range idList {
+		_idList = append(_idList, id)
+	}
+	return r.search(elastic.NewTermsQuery("_id", _idList...), len(idList))
+}
+
+func (r EsRepository) ListScheduled(before time.Time) ([]models.ArticleModel, error) {
+	// 没有发布时间的待发布文章要人工审核发布
+	query := elastic.NewBoolQuery().
+		Must(elastic.NewTermQuery("status", int(ctype.ArticleReview))).
+		Must(elastic.NewExistsQuery("publish_at")).
+		Must(elastic.NewRangeQuery("publish_at").Lte(before.Format("2006-01-02 15:04:05")))
+	return r.search(query, 1000)
+}
+
+// search 不分页查询
+func (EsRepository) search(query elastic.Query, size int) (list []models.ArticleModel, err error) {
+	result, err := global.ESClient.
+		Search(models.ArticleModel{}.Index()).
+		Query(query).
+		Size(size).
+		Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	for _, hit := range result.Hits.Hits {
+		var article models.ArticleModel
+		err = json.Unmarshal(hit.Source, &article)
+		if err != nil {
+			global.Log.Error(err)
+			continue
+		}
+		article.ID = hit.Id
+		list = append(list, article)
+	}
+	return list, nil
+}
+
+func (EsRepository) Update(id string, data map[string]any) error {
+	_, err := global.ESClient.
+		Update().
+		Index(models.ArticleModel{}.Index()).
+		Id(id).
+		Doc(data).Refresh("true").
+		Do(context.Background())
+	return err
+}
+
+// addCountsScript 在es里直接累加，不用先查出旧值，老文章没有计数字段时按0算
+const addCountsScript = `
+for (entry in params.entrySet()) {
+  def old = ctx._source[entry.getKey()];
+  ctx._source[entry.getKey()] = (old == null ? 0 : old) + entry.getValue();
+}`
+
+func (EsRepository) AddCounts(deltaMap map[string]CountDelta) (failedIDList []string, err error) {
+	if len(deltaMap) == 0 {
+		return nil, nil
+	}
+	bulkService := global.ESClient.Bulk().Index(models.ArticleModel{}.Index())
+	for id, delta := range deltaMap {
+		script := elastic.NewScript(addCountsScript).Params(map[string]any{
+			"digg_count":    delta.DiggCount,
+			"look_count":    delta.LookCount,
+			"comment_count": delta.CommentCount,
+		})
+		bulkService.Add(elastic.NewBulkUpdateRequest().Id(id).Script(script))
+	}
+	result, err := bulkService.Do(context.Background())
+	if err != nil {
+		for id := range deltaMap {
+			failedIDList = append(failedIDList, id)
+		}
+		return failedIDList, err
+	}
+	for _, item := range result.Failed() {
+		// 文章已经删除的不需要重试
+		if item.Status == 404 {
+			continue
+		}
+		failedIDList = append(failedIDList, item.Id)
+	}
+	return failedIDList, nil
+}
+
+func (EsRepository) Remove(idList []string) (int, error) {
+	bulkService := global.ESClient.Bulk().Index(models.ArticleModel{}.Index()).Refresh("true")
+	for _, id := range idList {
+		bulkService.Add(elastic.NewBulkDeleteRequest().Id(id))
+	}
+	result, err := bulkService.Do(context.Background())
+	if err != nil {
+		return 0, err
+	}
+	return len(result.Succeeded()), nil
+}
+
+func (EsRepository) Count() (int, error) {
+	count, err := global.ESClient.
+		Count(models.ArticleModel{}.Index()).
+		Query(elastic.NewMatchAllQuery()).
+		Do(context.Background())
+	return int(count), err
+}
+
+type bucketsType struct {
+	Buckets []struct {
+		KeyAsString string `json:"key_as_string"`
+		Key         any    `json:"key"`
+		DocCount    int    `json:"doc_count"`
+	} `json:"buckets"`
+}
+
+// CountByCreated 按区间边界做date_range聚合，只返回每个区间的数量
+func (EsRepository) CountByCreated(boundList []time.Time) ([]int, error) {
+	if len(boundList) < 2 {
+		return nil, nil
+	}
+	format := "2006-01-02 15:04:05"
+	// created_at存的是服务器本地时间，边界也要转成本地时间
+	agg := elastic.NewDateRangeAggregation().Field("created_at").Format("yyyy-MM-dd HH:mm:ss")
+	for i := 0; i+1 < len(boundList); i++ {
+		agg = agg.AddRange(boundList[i].In(time.Local).Format(format), boundList[i+1].In(time.Local).Format(format))
+	}
+	result, err := global.ESClient.
+		Search(models.ArticleModel{}.Index()).
+		Query(elastic.NewMatchAllQuery()).
+		Aggregation("created_at", agg).
+		Size(0).
+		Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	ranges, ok := result.Aggregations.DateRange("created_at")
+	if !ok {
+		return nil, errors.New("文章创建时间聚合结果为空")
+	}
+	// 区间按起点排序返回，和边界的顺序一致
+	var countList = make([]int, len(boundList)-1)
+	for i, bucket := range ranges.Buckets {
+		if i < len(countList) {
+			countList[i] = int(bucket.DocCount)
+		}
+	}
+	return countList, nil
+}
+
+func (EsRepository) Calendar(start, end time.Time) (map[string]int, error) {
+	// 按时间聚合
+	agg := elastic.NewDateHistogramAggregation().Field("created_at").CalendarInterval("day")
+
+	// 时间段搜索
+	format := "2006-01-02 15:04:05"
+	// lt 小于 gt 大于
+	query := elastic.NewBoolQuery().Must(elastic.NewRangeQuery("created_at").
+		Gte(start.Format(format)).
+		Lte(end.Format(format)))
+	FilterPublished(query)
+
+	result, err := global.ESClient.
+		Search(models.ArticleModel{}.Index()).
+		Query(query).
+		Aggregation("calendar", agg).
+		Size(0).
+		Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	var data bucketsType
+	_ = json.Unmarshal(result.Aggregations["calendar"], &data)
+	var dateCount = map[string]int{}
+	for _, bucket := range data.Buckets {
+		Time, _ := time.Parse(format, bucket.KeyAsString)
+		dateCount[Time.Format("2006-01-02")] = bucket.DocCount
+	}
+	return dateCount, nil
+}
+
+type tagsType struct {
+	Buckets []struct {
+		Key      string `json:"key"`
+		DocCount int    `json:"doc_count"`
+		Articles struct {
+			Buckets []struct {
+				Key      string `json:"key"`
+				DocCount int    `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"articles"`
+	} `json:"buckets"`
+}
+
+func (EsRepository) TagList(page, limit int) (list []TagCount, count int64, err error) {
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	query := FilterPublished(elastic.NewBoolQuery())
+	result, err := global.ESClient.
+		Search(models.ArticleModel{}.Index()).
+		Query(query).
+		Aggregation("tags", elastic.NewCardinalityAggregation().Field("tags")).
+		Size(0).
+		Do(context.Background())
+	if err != nil {
+		return nil, 0, err
+	}
+	cTag, _ := result.Aggregations.Cardinality("tags")
+	if cTag != nil && cTag.Value != nil {
+		count = int64(*cTag.Value)
+	}
+
+	agg := elastic.NewTermsAggregation().Field("tags").Size(offset + limit)
+	agg.SubAggregation("articles", elastic.NewTermsAggregation().Field("keyword"))
+	agg.SubAggregation("page", elastic.NewBucketSortAggregation().From(offset).Size(limit))
+	result, err = global.ESClient.
+		Search(models.ArticleModel{}.Index()).
+		Query(query).
+		Aggregation("tags", agg).
+		Size(0).
+		Do(context.Background())
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var tagType tagsType
+	_ = json.Unmarshal(result.Aggregations["tags"], &tagType)
+	for _, bucket := range tagType.Buckets {
+		var titleList []string
+		for _, s := range bucket.Articles.Buckets {
+			titleList = append(titleList, s.Key)
+		}
+		list = append(list, TagCount{
+			Tag:       bucket.Key,
+			Count:     bucket.DocCount,
+			TitleList: titleList,
+		})
+	}
+	return list, count, nil
+}
+
+func (r EsRepository) TagNameList() ([]string, error) {
+	return r.terms("tags", elastic.NewBoolQuery())
+}
+
+func (r EsRepository) CategoryList() ([]string, error) {
+	return r.terms("category", FilterPublished(elastic.NewBoolQuery()))
+}
+
+// terms 某个字段的全部取值
+func (EsRepository) terms(field string, query elastic.Query) (keyList []string, err error) {
+	result, err := global.ESClient.
+		Search(models.ArticleModel{}.Index()).
+		Query(query).
+		Aggregation(field, elastic.NewTermsAggregation().Field(field)).
+		Size(0).
+		Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	var data bucketsType
+	_ = json.Unmarshal(result.Aggregations[field], &data)
+	for _, bucket := range data.Buckets {
+		key, _ := bucket.Key.(string)
+		keyList = append(keyList, key)
+	}
+	return keyList, nil
+}
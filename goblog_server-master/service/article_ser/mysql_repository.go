@@ -0,0 +1,277 @@
+package article_ser
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/service/common"
+	"gvb_server/utils"
+	"gvb_server/utils/random"
+	"time"
+)
+
+// MysqlRepository 文章存在mysql的article_models，标签冗余一份到article_tag_models方便分组
+type MysqlRepository struct {
+}
+
+// 允许关键字搜索的字段
+var searchFieldList = []string{"title", "abstract", "content", "category"}
+
+// publishedScope 过滤掉未发布的文章
+func publishedScope(db *gorm.DB) *gorm.DB {
+	return db.Where("article_models.status not in ?", ctype.HiddenArticleStatus())
+}
+
+func (MysqlRepository) Create(article *models.ArticleModel) error {
+	article.ID = random.RandString(20)
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(article).Error
+		if err != nil {
+			return err
+		}
+		return saveTags(tx, article.ID, article.Tags)
+	})
+}
+
+// saveTags 重建文章的标签关联
+func saveTags(tx *gorm.DB, articleID string, tags ctype.Array) error {
+	err := tx.Where("article_id = ?", articleID).Delete(&models.ArticleTagModel{}).Error
+	if err != nil {
+		return err
+	}
+	var tagList []models.ArticleTagModel
+	for _, tag := range tags {
+		tagList = append(tagList, models.ArticleTagModel{ArticleID: articleID, Tag: tag})
+	}
+	if len(tagList) == 0 {
+		return nil
+	}
+	return tx.Create(&tagList).Error
+}
+
+func (MysqlRepository) Detail(id string) (model models.ArticleModel, err error) {
+	err = global.DB.Take(&model, "id = ?", id).Error
+	if err != nil {
+		return model, errors.New("文章不存在")
+	}
+	return
+}
+
+func (MysqlRepository) DetailByTitle(title string) (model models.ArticleModel, err error) {
+	err = global.DB.Take(&model, "keyword = ?", title).Error
+	if err != nil {
+		return model, errors.New("文章不存在")
+	}
+	return
+}
+
+func (MysqlRepository) IsExistTitle(title string) bool {
+	var count int64
+	global.DB.Model(models.ArticleModel{}).Where("keyword = ?", title).Count(&count)
+	return count > 0
+}
+
+func (MysqlRepository) List(option ListOption) (list []models.ArticleModel, count int, err error) {
+	query := global.DB.Model(models.ArticleModel{})
+	if option.Key != "" {
+		like := global.DB.Where("1 = 0")
+		for _, field := range option.Fields {
+			if !utils.InList(field, searchFieldList) {
+				continue
+			}
+			like = like.Or(fmt.Sprintf("%s like ?", field), fmt.Sprintf("%%%s%%", option.Key))
+		}
+		query = query.Where(like)
+	}
+	if option.Tag != "" {
+		query = query.Where("id in (?)", global.DB.Model(models.ArticleTagModel{}).
+			Select("article_id").Where("tag = ?", option.Tag))
+	}
+	if option.Category != "" {
+		query = query.Where("category = ?", option.Category)
+	}
+	if option.UserID != 0 {
+		query = query.Where("user_id = ?", option.UserID)
+	}
+	if option.Status != 0 {
+		query = query.Where("status = ?", option.Status)
+	}
+	if !option.ShowHidden {
+		query = query.Scopes(publishedScope)
+	}
+	// 下面count和find共用条件，需要新的会话
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	err = query.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	field, ascending := option.sortField()
+	order := field + " desc"
+	if ascending {
+		order = field + " asc"
+	}
+	list = []models.ArticleModel{}
+	err = query.Order(order).Offset(option.GetForm()).Limit(option.Limit).Find(&list).Error
+	return list, int(total), err
+}
+
+func (MysqlRepository) ListByIDList(idList []string) (list []models.ArticleModel, err error) {
+	err = global.DB.Find(&list, "id in ?", idList).Error
+	return
+}
+
+func (MysqlRepository) ListScheduled(before time.Time) (list []models.ArticleModel, err error) {
+	// 没有发布时间的待发布文章要人工审核发布
+	err = global.DB.Limit(1000).Find(&list, "status = ? and publish_at <> '' and publish_at <= ?",
+		ctype.ArticleReview, before.Format("2006-01-02 15:04:05")).Error
+	return
+}
+
+func (MysqlRepository) Update(id string, data map[string]any) error {
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.ArticleModel{ID: id}).Updates(data).Error
+		if err != nil {
+			return err
+		}
+		tags, ok := data["tags"].(ctype.Array)
+		if !ok {
+			return nil
+		}
+		return saveTags(tx, id, tags)
+	})
+}
+
+func (MysqlRepository) AddCounts(deltaMap map[string]CountDelta) (failedIDList []string, err error) {
+	for id, delta := range deltaMap {
+		// 文章已经删除的不需要重试，这里不看影响的行数
+		err = global.DB.Model(&models.ArticleModel{ID: id}).Updates(map[string]any{
+			"digg_count":    gorm.Expr("digg_count + ?", delta.DiggCount),
+			"look_count":    gorm.Expr("look_count + ?", delta.LookCount),
+			"comment_count": gorm.Expr("comment_count + ?", delta.CommentCount),
+		}).Error
+		if err != nil {
+			global.Log.Error(err)
+			failedIDList = append(failedIDList, id)
+		}
+	}
+	return failedIDList, nil
+}
+
+func (MysqlRepository) Remove(idList []string) (count int, err error) {
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.ArticleModel{}, "id in ?", idList)
+		if result.Error != nil {
+			return result.Error
+		}
+		count = int(result.RowsAffected)
+		return tx.Delete(&models.ArticleTagModel{}, "article_id in ?", idList).Error
+	})
+	return count, err
+}
+
+func (MysqlRepository) Count() (int, error) {
+	var count int64
+	err := global.DB.Model(models.ArticleModel{}).Count(&count).Error
+	return int(count), err
+}
+
+// CountByCreated 在数据库里按区间计数
+func (MysqlRepository) CountByCreated(boundList []time.Time) ([]int, error) {
+	format := "2006-01-02 15:04:05"
+	// created_at存的是服务器本地时间的字符串，边界也要转成本地时间
+	var list = make([]any, 0, len(boundList))
+	for _, bound := range boundList {
+		list = append(list, bound.In(time.Local).Format(format))
+	}
+	return common.ComCountByRange(global.DB.Model(models.ArticleModel{}), "created_at", list)
+}
+
+func (MysqlRepository) Calendar(start, end time.Time) (map[string]int, error) {
+	type DateCount struct {
+		Date  string
+		Count int
+	}
+	// created_at 存的是 2006-01-02 15:04:05 格式的字符串，截取前10位就是日期
+	var dateCountList []DateCount
+	format := "2006-01-02 15:04:05"
+	err := global.DB.Model(models.ArticleModel{}).
+		Scopes(publishedScope).
+		Where("created_at >= ? and created_at <= ?", start.Format(format), end.Format(format)).
+		Select("substr(created_at, 1, 10) as date", "count(id) as count").
+		Group("date").
+		Scan(&dateCountList).Error
+	if err != nil {
+		return nil, err
+	}
+	var dateCount = map[string]int{}
+	for _, d := range dateCountList {
+		dateCount[d.Date] = d.Count
+	}
+	return dateCount, nil
+}
+
+func (MysqlRepository) TagList(page, limit int) (list []TagCount, count int64, err error) {
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	query := func() *gorm.DB {
+		return global.DB.Model(models.ArticleTagModel{}).
+			Joins("join article_models on article_models.id = article_tag_models.article_id").
+			Scopes(publishedScope)
+	}
+	err = query().Distinct("article_tag_models.tag").Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	err = query().
+		Select("article_tag_models.tag as tag", "count(*) as count").
+		Group("article_tag_models.tag").
+		Order("count desc").
+		Offset(offset).Limit(limit).
+		Scan(&list).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// 标签下的文章标题
+	var tagNameList []string
+	for _, tag := range list {
+		tagNameList = append(tagNameList, tag.Tag)
+	}
+	type TagTitle struct {
+		Tag   string
+		Title string
+	}
+	var tagTitleList []TagTitle
+	query().Where("article_tag_models.tag in ?", tagNameList).
+		Select("article_tag_models.tag as tag", "article_models.keyword as title").
+		Scan(&tagTitleList)
+	var titleMap = map[string][]string{}
+	for _, t := range tagTitleList {
+		titleMap[t.Tag] = append(titleMap[t.Tag], t.Title)
+	}
+	for i := range list {
+		list[i].TitleList = titleMap[list[i].Tag]
+	}
+	return list, count, nil
+}
+
+func (MysqlRepository) TagNameList() (tagList []string, err error) {
+	err = global.DB.Model(models.ArticleTagModel{}).Distinct("tag").Pluck("tag", &tagList).Error
+	return
+}
+
+func (MysqlRepository) CategoryList() (categoryList []string, err error) {
+	err = global.DB.Model(models.ArticleModel{}).
+		Scopes(publishedScope).
+		Where("category <> ''").
+		Distinct("category").
+		Pluck("category", &categoryList).Error
+	return
+}
@@ -0,0 +1,117 @@
+package article_ser
+
+import (
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/utils"
+	"strings"
+	"time"
+)
+
+const (
+	StorageES    = "es"    // 文章存在es，默认
+	StorageMysql = "mysql" // 文章存在mysql，不需要es集群
+)
+
+// ArticleRepository 文章存储，es和mysql各有一个实现
+type ArticleRepository interface {
+	// Create 添加文章，成功后回写文章id
+	Create(article *models.ArticleModel) error
+	// Detail 根据id查
+	Detail(id string) (models.ArticleModel, error)
+	// DetailByTitle 根据标题查
+	DetailByTitle(title string) (models.ArticleModel, error)
+	// IsExistTitle 标题是否已存在
+	IsExistTitle(title string) bool
+	// List 分页查询
+	List(option ListOption) (list []models.ArticleModel, count int, err error)
+	// ListByIDList 根据id列表查
+	ListByIDList(idList []string) ([]models.ArticleModel, error)
+	// ListScheduled 发布时间已到的待发布文章
+	ListScheduled(before time.Time) ([]models.ArticleModel, error)
+	// Update 更新部分字段
+	Update(id string, data map[string]any) error
+	// AddCounts 批量累加点赞、浏览、评论数，返回没有更新成功的id
+	AddCounts(deltaMap map[string]CountDelta) (failedIDList []string, err error)
+	// Remove 批量删除，返回删除的数量
+	Remove(idList []string) (int, error)
+	// Count 文章总数
+	Count() (int, error)
+	// CountByCreated 按创建时间分区间统计文章数，相邻的两个边界是一个区间，包含起点不包含终点
+	CountByCreated(boundList []time.Time) ([]int, error)
+	// Calendar 已发布文章按天统计 日期 -> 文章数
+	Calendar(start, end time.Time) (map[string]int, error)
+	// TagList 已发布文章按标签分组
+	TagList(page, limit int) (list []TagCount, count int64, err error)
+	// TagNameList 文章用到的标签
+	TagNameList() ([]string, error)
+	// CategoryList 已发布文章的分类
+	CategoryList() ([]string, error)
+}
+
+// ListOption 文章列表查询参数
+type ListOption struct {
+	models.PageInfo
+	Fields     []string            // 关键字搜索的字段
+	Tag        string              // 按标签搜
+	Category   string              // 按分类搜
+	UserID     uint                // 按作者搜
+	Status     ctype.ArticleStatus // 按状态搜
+	ShowHidden bool                // 是否显示未发布的文章
+}
+
+// CountDelta 文章计数的增量
+type CountDelta struct {
+	DiggCount    int
+	LookCount    int
+	CommentCount int
+}
+
+// TagCount 标签下的文章
+type TagCount struct {
+	Tag       string   `json:"tag"`
+	Count     int      `json:"count"`
+	TitleList []string `json:"title_list"`
+}
+
+// GetForm 分页偏移量
+func (o *ListOption) GetForm() int {
+	if o.Page == 0 {
+		o.Page = 1
+	}
+	if o.Limit == 0 {
+		o.Limit = 10
+	}
+	return (o.Page - 1) * o.Limit
+}
+
+// sortField 解析排序参数 "created_at desc"
+func (o *ListOption) sortField() (field string, ascending bool) {
+	field = "created_at"
+	_list := strings.Split(o.Sort, " ")
+	if len(_list) == 2 && (_list[1] == "desc" || _list[1] == "asc") && utils.InList(_list[0], sortFieldList) {
+		field = _list[0]
+		ascending = _list[1] == "asc"
+	}
+	return
+}
+
+// 允许排序的字段
+var sortFieldList = []string{
+	"created_at", "updated_at", "publish_at",
+	"look_count", "comment_count", "digg_count", "collects_count",
+}
+
+// NewRepository 根据配置选择文章存储
+func NewRepository() ArticleRepository {
+	if global.Config.System.ArticleStorage == StorageMysql {
+		return MysqlRepository{}
+	}
+	return EsRepository{}
+}
+
+// IsES 文章是否存在es中
+func IsES() bool {
+	return global.Config.System.ArticleStorage != StorageMysql
+}
@@ -0,0 +1,96 @@
+package article_ser
+
+import (
+	"gorm.io/gorm"
+	"gvb_server/global"
+	"gvb_server/models"
+	"strings"
+)
+
+// maxRevisionRetry 同时修改一篇文章时版本号冲突的重试次数
+const maxRevisionRetry = 3
+
+// CreateRevision 把文章当前的内容保存为一个新的修订版本
+// 版本号在事务里取最大值+1，同时修改时靠唯一索引发现冲突，冲突了重新取版本号
+func (ArticleService) CreateRevision(article models.ArticleModel, userID uint, nickName, summary string) (revision models.ArticleRevisionModel, err error) {
+	for i := 0; i < maxRevisionRetry; i++ {
+		revision = models.ArticleRevisionModel{
+			ArticleID:    article.ID,
+			Title:        article.Title,
+			Abstract:     article.Abstract,
+			Content:      article.Content,
+			Category:     article.Category,
+			Tags:         article.Tags,
+			BannerID:     article.BannerID,
+			UserID:       userID,
+			UserNickName: nickName,
+			Summary:      summary,
+		}
+		err = global.DB.Transaction(func(tx *gorm.DB) error {
+			var version int
+			err := tx.Model(models.ArticleRevisionModel{}).
+				Where("article_id = ?", article.ID).
+				Select("coalesce(max(version), 0)").
+				Scan(&version).Error
+			if err != nil {
+				return err
+			}
+			revision.Version = version + 1
+			return tx.Create(&revision).Error
+		})
+		if err == nil || !revisionExists(article.ID, revision.Version) {
+			return revision, err
+		}
+	}
+	return revision, err
+}
+
+// revisionExists 版本号是否已经被占用
+func revisionExists(articleID string, version int) bool {
+	if version == 0 {
+		return false
+	}
+	var count int64
+	global.DB.Model(models.ArticleRevisionModel{}).
+		Where("article_id = ? and version = ?", articleID, version).
+		Count(&count)
+	return count > 0
+}
+
+// EnsureInitRevision 没有修订记录的老文章，先把修改前的内容存为第一个版本
+func (s ArticleService) EnsureInitRevision(article models.ArticleModel) error {
+	var count int64
+	global.DB.Model(models.ArticleRevisionModel{}).Where("article_id = ?", article.ID).Count(&count)
+	if count > 0 {
+		return nil
+	}
+	_, err := s.CreateRevision(article, article.UserID, article.UserNickName, "初始版本")
+	return err
+}
+
+// ChangeSummary 根据修改前后的文章生成修改说明
+func (ArticleService) ChangeSummary(oldArticle, newArticle models.ArticleModel) string {
+	var changes []string
+	if oldArticle.Title != newArticle.Title {
+		changes = append(changes, "标题")
+	}
+	if oldArticle.Abstract != newArticle.Abstract {
+		changes = append(changes, "简介")
+	}
+	if oldArticle.Content != newArticle.Content {
+		changes = append(changes, "正文")
+	}
+	if oldArticle.Category != newArticle.Category {
+		changes = append(changes, "分类")
+	}
+	if strings.Join(oldArticle.Tags, ",") != strings.Join(newArticle.Tags, ",") {
+		changes = append(changes, "标签")
+	}
+	if oldArticle.BannerID != newArticle.BannerID {
+		changes = append(changes, "封面")
+	}
+	if len(changes) == 0 {
+		return "未修改内容"
+	}
+	return "修改了" + strings.Join(changes, "、")
+}
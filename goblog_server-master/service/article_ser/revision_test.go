@@ -0,0 +1,25 @@
+package article_ser
+
+import (
+	"gvb_server/internal/testdb"
+	"gvb_server/models"
+	"testing"
+)
+
+func TestCreateRevision(t *testing.T) {
+	db := testdb.Open(t, &models.ArticleRevisionModel{})
+
+	s := ArticleService{}
+	article := models.ArticleModel{ID: "a1", Title: "a1"}
+	for want := 1; want <= 2; want++ {
+		revision, err := s.CreateRevision(article, 1, "admin", "修改")
+		if err != nil || revision.Version != want {
+			t.Fatalf("version %d: %+v %v", want, revision, err)
+		}
+	}
+	// 查询版本号失败时返回错误，不会从1开始重新编号
+	db.Migrator().DropTable(&models.ArticleRevisionModel{})
+	if _, err := s.CreateRevision(article, 1, "admin", "修改"); err == nil {
+		t.Fatal("missing table")
+	}
+}
@@ -0,0 +1,95 @@
+package chat_ser
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"gvb_server/global"
+	"gvb_server/internal/testdb"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChatGroup(t *testing.T) {
+	testdb.Open(t, &models.ChatModel{})
+	global.Redis = nil
+
+	upGrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upGrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		roomID, _ := strconv.Atoi(r.URL.Query().Get("room_id"))
+		Serve(NewClient(conn, uint(roomID), Identity{NickName: r.URL.Query().Get("name"), IP: "127.0.0.1", Addr: "内网地址"}))
+	}))
+	defer server.Close()
+
+	dial := func(name string, roomID uint) *websocket.Conn {
+		url := fmt.Sprintf("ws%s?name=%s&room_id=%d", strings.TrimPrefix(server.URL, "http"), name, roomID)
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+	read := func(conn *websocket.Conn) GroupResponse {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var response GroupResponse
+		if err := conn.ReadJSON(&response); err != nil {
+			t.Fatal(err)
+		}
+		return response
+	}
+
+	alice := dial("alice", 0)
+	defer alice.Close()
+	if r := read(alice); r.MsgType != ctype.SystemMsg || r.OnlineCount != 1 {
+		t.Fatalf("alice welcome: %+v", r)
+	}
+	read(alice) // alice 进入聊天室
+
+	bob := dial("bob", 0)
+	if r := read(bob); r.OnlineCount != 2 {
+		t.Fatalf("bob welcome: %+v", r)
+	}
+	if r := read(alice); r.MsgType != ctype.InRoomMsg || r.NickName != "bob" {
+		t.Fatalf("bob joined: %+v", r)
+	}
+	read(bob)
+
+	// 其他聊天室的人进出和发言互不影响
+	carol := dial("carol", 1)
+	defer carol.Close()
+	if r := read(carol); r.RoomID != 1 || r.OnlineCount != 1 {
+		t.Fatalf("carol welcome: %+v", r)
+	}
+	read(carol)
+
+	byteData, _ := json.Marshal(GroupRequest{MsgType: ctype.TextMsg, Content: "hello"})
+	bob.WriteMessage(websocket.TextMessage, byteData)
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		if r := read(conn); r.MsgType != ctype.TextMsg || r.Content != "hello" || r.NickName != "bob" {
+			t.Fatalf("text: %+v", r)
+		}
+	}
+
+	bob.Close()
+	if r := read(alice); r.MsgType != ctype.OutRoomMsg || r.OnlineCount != 1 {
+		t.Fatalf("bob left: %+v", r)
+	}
+
+	list, count, _ := RecordList(0, models.PageInfo{Page: 1, Limit: 10})
+	if count != 4 || list[0].MsgType != ctype.OutRoomMsg {
+		t.Fatalf("lobby records: %d %+v", count, list)
+	}
+	if _, count, _ = RecordList(1, models.PageInfo{Page: 1}); count != 1 {
+		t.Fatalf("room records: %d", count)
+	}
+}
@@ -0,0 +1,202 @@
+package chat_ser
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"strings"
+	"time"
+)
+
+const (
+	writeWait      = 10 * time.Second  // 写一条消息的超时
+	pongWait       = 60 * time.Second  // 多久没收到pong算断开
+	pingPeriod     = pongWait * 9 / 10 // 发ping的间隔，要小于pongWait
+	maxMessageSize = 4096              // 客户端一条消息的最大字节数
+	sendBuffer     = 64                // 每个连接待发送的消息数，满了说明客户端太慢，直接断开
+)
+
+// GroupRequest 群聊入参
+type GroupRequest struct {
+	Content string        `json:"content"`  // 聊天的内容
+	MsgType ctype.MsgType `json:"msg_type"` // 聊天类型
+}
+
+// GroupResponse 群聊出参
+type GroupResponse struct {
+	RoomID      uint          `json:"room_id"`      // 聊天室id，0是大厅
+	UserID      uint          `json:"user_id"`      // 登录用户的id，匿名为0
+	NickName    string        `json:"nick_name"`    // 匿名时随机生成，登录用户是昵称
+	Avatar      string        `json:"avatar"`       // 头像
+	MsgType     ctype.MsgType `json:"msg_type"`     // 聊天类型
+	Content     string        `json:"content"`      // 聊天的内容
+	OnlineCount int           `json:"online_count"` // 聊天室的在线人数，整个集群的
+	Date        time.Time     `json:"created_at"`   // 消息发送时间
+}
+
+// Identity 聊天的身份，登录用户用真实的昵称和头像，匿名用户随机生成
+type Identity struct {
+	UserID   uint // 匿名为0
+	NickName string
+	Avatar   string
+	IP       string
+	Addr     string
+}
+
+// Client 一个聊天连接
+// 读在Serve所在的协程，写只在writePump协程，其他地方通过send发消息
+type Client struct {
+	Identity
+	ID     string // 集群内唯一，在线人数用
+	RoomID uint   // 所在的聊天室，0是大厅
+	conn   *websocket.Conn
+	send   chan []byte
+}
+
+// NewClient 升级后的websocket连接，进入roomID的聊天室
+func NewClient(conn *websocket.Conn, roomID uint, identity Identity) *Client {
+	return &Client{
+		Identity: identity,
+		ID:       newClientID(),
+		RoomID:   roomID,
+		conn:     conn,
+		send:     make(chan []byte, sendBuffer),
+	}
+}
+
+// Serve 处理连接直到断开，会阻塞
+func Serve(client *Client) {
+	go client.writePump()
+	join(client)
+	client.readPump()
+	leave(client)
+}
+
+// readPump 读客户端的消息，出错或者超时没收到pong时返回
+func (c *Client) readPump() {
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, p, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.handle(p)
+	}
+}
+
+// writePump 唯一写连接的协程，send关闭后发close帧并关闭连接
+func (c *Client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+	for {
+		select {
+		case byteData, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, byteData); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// enqueue 放进待发送的队列，队列满了断开连接，readPump出错后会走正常的离开流程
+func (c *Client) enqueue(byteData []byte) {
+	select {
+	case c.send <- byteData:
+	default:
+		c.conn.Close()
+	}
+}
+
+// handle 判断类型，分发逻辑
+func (c *Client) handle(p []byte) {
+	var request GroupRequest
+	err := json.Unmarshal(p, &request)
+	if err != nil {
+		// 参数绑定失败
+		c.reply("参数绑定失败", true)
+		return
+	}
+	switch request.MsgType {
+	case ctype.TextMsg:
+		if strings.TrimSpace(request.Content) == "" {
+			c.reply("消息不能为空", false)
+			return
+		}
+		c.broadcast(ctype.TextMsg, request.Content)
+	default:
+		c.reply("消息类型错误", true)
+	}
+}
+
+// reply 只发给自己的系统消息
+func (c *Client) reply(content string, isSave bool) {
+	response := GroupResponse{
+		RoomID:      c.RoomID,
+		UserID:      c.UserID,
+		NickName:    c.NickName,
+		Avatar:      c.Avatar,
+		MsgType:     ctype.SystemMsg,
+		Content:     content,
+		OnlineCount: OnlineCount(c.RoomID),
+	}
+	byteData, _ := json.Marshal(response)
+	c.enqueue(byteData)
+	if isSave {
+		c.save(response, false)
+	}
+}
+
+// broadcast 保存后发给集群里同一个聊天室的所有连接
+func (c *Client) broadcast(msgType ctype.MsgType, content string) {
+	response := GroupResponse{
+		RoomID:      c.RoomID,
+		UserID:      c.UserID,
+		NickName:    c.NickName,
+		Avatar:      c.Avatar,
+		MsgType:     msgType,
+		Content:     content,
+		OnlineCount: OnlineCount(c.RoomID),
+		Date:        time.Now(),
+	}
+	c.save(response, true)
+	err := publish(response)
+	if err != nil {
+		global.Log.Error(err)
+	}
+}
+
+func (c *Client) save(response GroupResponse, isGroup bool) {
+	err := global.DB.Create(&models.ChatModel{
+		NickName: response.NickName,
+		Avatar:   response.Avatar,
+		Content:  response.Content,
+		IP:       c.IP,
+		Addr:     c.Addr,
+		ISGroup:  isGroup,
+		MsgType:  response.MsgType,
+		RoomID:   c.RoomID,
+		UserID:   c.UserID,
+	}).Error
+	if err != nil {
+		global.Log.Error(err)
+	}
+}
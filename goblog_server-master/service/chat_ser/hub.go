@@ -0,0 +1,121 @@
+package chat_ser
+
+import (
+	"encoding/json"
+	"fmt"
+	"gvb_server/global"
+	"gvb_server/models/ctype"
+	"sync"
+)
+
+// hub 本实例上的连接，按聊天室分组
+type hub struct {
+	sync.RWMutex
+	roomMap map[uint]map[*Client]struct{}
+}
+
+var localHub = hub{roomMap: map[uint]map[*Client]struct{}{}}
+
+// join 加入聊天室，先告诉自己，再通知聊天室里所有人
+func join(client *Client) {
+	localHub.Lock()
+	if localHub.roomMap[client.RoomID] == nil {
+		localHub.roomMap[client.RoomID] = map[*Client]struct{}{}
+	}
+	localHub.roomMap[client.RoomID][client] = struct{}{}
+	localHub.Unlock()
+	addOnline(client)
+
+	global.Log.Infof("%s %s 进入聊天室 %d", client.IP, client.NickName, client.RoomID)
+	client.reply("进入聊天室", false)
+	client.broadcast(ctype.InRoomMsg, fmt.Sprintf("%s 进入聊天室", client.NickName))
+}
+
+// leave 离开聊天室，移出后关闭send，之后不会再有消息发给这个连接
+func leave(client *Client) {
+	localHub.Lock()
+	clientMap := localHub.roomMap[client.RoomID]
+	delete(clientMap, client)
+	if len(clientMap) == 0 {
+		delete(localHub.roomMap, client.RoomID)
+	}
+	close(client.send)
+	localHub.Unlock()
+	removeOnline(client)
+
+	client.broadcast(ctype.OutRoomMsg, fmt.Sprintf("%s 离开聊天室", client.NickName))
+}
+
+// deliver 发给本实例上聊天室里所有的连接
+func deliver(roomID uint, byteData []byte) {
+	localHub.RLock()
+	defer localHub.RUnlock()
+	for client := range localHub.roomMap[roomID] {
+		client.enqueue(byteData)
+	}
+}
+
+// localClientList 本实例上的连接
+func localClientList() (clientList []*Client) {
+	localHub.RLock()
+	defer localHub.RUnlock()
+	for _, clientMap := range localHub.roomMap {
+		for client := range clientMap {
+			clientList = append(clientList, client)
+		}
+	}
+	return clientList
+}
+
+// localRoomCount 本实例上聊天室的连接数
+func localRoomCount(roomID uint) int {
+	localHub.RLock()
+	defer localHub.RUnlock()
+	return len(localHub.roomMap[roomID])
+}
+
+// channel 多个实例之间转发群聊消息的redis频道
+const channel = "gvb_chat"
+
+// message 频道里的消息，data是发给客户端的GroupResponse
+type message struct {
+	RoomID uint            `json:"room_id"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// publish 通过redis发给所有实例，没有连接redis时只发给本实例
+func publish(response GroupResponse) error {
+	byteData, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	if global.Redis == nil {
+		deliver(response.RoomID, byteData)
+		return nil
+	}
+	byteData, err = json.Marshal(message{RoomID: response.RoomID, Data: byteData})
+	if err != nil {
+		return err
+	}
+	return global.Redis.Publish(channel, byteData).Err()
+}
+
+// Run 订阅redis频道，把所有实例的消息转给本实例的连接，同时定时刷新在线状态，启动时在协程里调用
+func Run() {
+	if global.Redis == nil {
+		return
+	}
+	go refreshOnline()
+	pubsub := global.Redis.Subscribe(channel)
+	defer pubsub.Close()
+	// Channel断线会自动重连
+	for redisMsg := range pubsub.Channel() {
+		var msg message
+		err := json.Unmarshal([]byte(redisMsg.Payload), &msg)
+		if err != nil {
+			global.Log.Errorf("群聊消息解析失败 %s", err)
+			continue
+		}
+		deliver(msg.RoomID, msg.Data)
+	}
+}
@@ -0,0 +1,95 @@
+package chat_ser
+
+import (
+	"fmt"
+	"github.com/go-redis/redis"
+	"gvb_server/global"
+	"gvb_server/utils/random"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// 每个聊天室在线的连接存在redis的有序集合里，分数是最后一次心跳的时间
+// 每个实例定时刷新自己的连接，实例挂掉后它的连接超时自动不算在线
+const (
+	onlinePrefix  = "chat_online"
+	onlineTimeout = 90 * time.Second
+	onlineRefresh = 30 * time.Second
+)
+
+// instanceID 本实例的id，连接id的前缀
+var instanceID = random.RandString(8)
+
+var clientCount uint64
+
+func newClientID() string {
+	return fmt.Sprintf("%s_%d", instanceID, atomic.AddUint64(&clientCount, 1))
+}
+
+// onlineKey 聊天室在线连接的有序集合
+func onlineKey(roomID uint) string {
+	return fmt.Sprintf("%s_%d", onlinePrefix, roomID)
+}
+
+func addOnline(client *Client) {
+	if global.Redis == nil {
+		return
+	}
+	err := global.Redis.ZAdd(onlineKey(client.RoomID), redis.Z{Score: float64(time.Now().Unix()), Member: client.ID}).Err()
+	if err != nil {
+		global.Log.Error(err)
+	}
+}
+
+func removeOnline(client *Client) {
+	if global.Redis == nil {
+		return
+	}
+	err := global.Redis.ZRem(onlineKey(client.RoomID), client.ID).Err()
+	if err != nil {
+		global.Log.Error(err)
+	}
+}
+
+// refreshOnline 定时刷新本实例所有连接的心跳时间
+func refreshOnline() {
+	ticker := time.NewTicker(onlineRefresh)
+	defer ticker.Stop()
+	for range ticker.C {
+		clientList := localClientList()
+		if len(clientList) == 0 {
+			continue
+		}
+		now := float64(time.Now().Unix())
+		var roomMemberMap = map[uint][]redis.Z{}
+		for _, client := range clientList {
+			roomMemberMap[client.RoomID] = append(roomMemberMap[client.RoomID], redis.Z{Score: now, Member: client.ID})
+		}
+		pipe := global.Redis.Pipeline()
+		for roomID, memberList := range roomMemberMap {
+			pipe.ZAdd(onlineKey(roomID), memberList...)
+		}
+		_, err := pipe.Exec()
+		if err != nil {
+			global.Log.Error(err)
+		}
+	}
+}
+
+// OnlineCount 聊天室在整个集群的在线人数，没有连接redis时是本实例的
+func OnlineCount(roomID uint) int {
+	if global.Redis == nil {
+		return localRoomCount(roomID)
+	}
+	expired := strconv.FormatInt(time.Now().Add(-onlineTimeout).Unix(), 10)
+	pipe := global.Redis.TxPipeline()
+	pipe.ZRemRangeByScore(onlineKey(roomID), "-inf", "("+expired)
+	count := pipe.ZCard(onlineKey(roomID))
+	_, err := pipe.Exec()
+	if err != nil {
+		global.Log.Error(err)
+		return localRoomCount(roomID)
+	}
+	return int(count.Val())
+}
@@ -0,0 +1,87 @@
+package chat_ser
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gvb_server/global"
+	"gvb_server/models"
+)
+
+// LobbyName 默认聊天室的名称，id为0
+const LobbyName = "大厅"
+
+var ErrRoomExists = errors.New("聊天室名称已存在")
+
+// RoomResponse 聊天室和在线人数
+type RoomResponse struct {
+	models.ChatRoomModel
+	OnlineCount int `json:"online_count"`
+}
+
+// RoomExists 聊天室是否存在，大厅一直存在
+func RoomExists(roomID uint) bool {
+	if roomID == 0 {
+		return true
+	}
+	var count int64
+	global.DB.Model(&models.ChatRoomModel{}).Where("id = ?", roomID).Count(&count)
+	return count > 0
+}
+
+// CreateRoom 创建聊天室
+func CreateRoom(room *models.ChatRoomModel) error {
+	var count int64
+	global.DB.Model(&models.ChatRoomModel{}).Where("name = ?", room.Name).Count(&count)
+	if count > 0 || room.Name == LobbyName {
+		return ErrRoomExists
+	}
+	return global.DB.Create(room).Error
+}
+
+// RoomList 所有聊天室，大厅在最前面
+func RoomList() (list []RoomResponse, err error) {
+	var roomList []models.ChatRoomModel
+	err = global.DB.Order("id asc").Find(&roomList).Error
+	if err != nil {
+		return nil, err
+	}
+	list = append(list, RoomResponse{
+		ChatRoomModel: models.ChatRoomModel{Name: LobbyName},
+		OnlineCount:   OnlineCount(0),
+	})
+	for _, room := range roomList {
+		list = append(list, RoomResponse{ChatRoomModel: room, OnlineCount: OnlineCount(room.ID)})
+	}
+	return list, nil
+}
+
+// RemoveRooms 删除聊天室和里面的聊天记录，返回删除的数量
+func RemoveRooms(idList []uint) (count int64, err error) {
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.ChatRoomModel{}, idList)
+		if result.Error != nil {
+			return result.Error
+		}
+		count = result.RowsAffected
+		return tx.Where("room_id in ?", idList).Delete(&models.ChatModel{}).Error
+	})
+	return count, err
+}
+
+// RecordList 聊天室的群聊记录，新的在前
+func RecordList(roomID uint, page models.PageInfo) (list []models.ChatModel, count int64, err error) {
+	query := global.DB.Model(&models.ChatModel{}).Where("room_id = ? and is_group = ?", roomID, true)
+	err = query.Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if page.Limit <= 0 {
+		page.Limit = 10
+	}
+	offset := (page.Page - 1) * page.Limit
+	if offset < 0 {
+		offset = 0
+	}
+	err = query.Order("created_at desc").Limit(page.Limit).Offset(offset).Find(&list).Error
+	return list, count, err
+}
@@ -0,0 +1,60 @@
+package comment_ser
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+)
+
+// ErrTooDeep 回复的层级超过了MaxDepth
+var ErrTooDeep = errors.New("回复的层级太深")
+
+// Create 添加评论，parent为nil时是根评论，没有设置审核状态的为已通过
+// 插入后才有id，在同一个事务里回写根评论id和路径，通过审核的回复父评论的回复数+1
+func (CommentService) Create(comment *models.CommentModel, parent *models.CommentModel) error {
+	if comment.Status == 0 {
+		comment.Status = ctype.CommentApproved
+	}
+	if parent != nil {
+		if parent.Depth+1 > MaxDepth {
+			return ErrTooDeep
+		}
+		comment.ParentCommentID = &parent.ID
+		comment.RootID = parent.RootID
+		comment.Depth = parent.Depth + 1
+	}
+	return global.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(comment).Error
+		if err != nil {
+			return err
+		}
+		if parent == nil {
+			comment.RootID = comment.ID
+			comment.Path = PathSegment(comment.ID)
+		} else {
+			comment.Path = parent.Path + PathSegment(comment.ID)
+		}
+		err = tx.Model(comment).Updates(map[string]any{
+			"root_id": comment.RootID,
+			"path":    comment.Path,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if parent == nil || comment.Status != ctype.CommentApproved {
+			return nil
+		}
+		return tx.Model(parent).Update("comment_count", gorm.Expr("comment_count + 1")).Error
+	})
+}
+
+// SubIDList 评论下所有回复的id，不包括自己，从深到浅
+func (CommentService) SubIDList(comment models.CommentModel) (idList []uint, err error) {
+	err = global.DB.Model(&models.CommentModel{}).
+		Where("root_id = ? and path like ? and id <> ?", comment.RootID, comment.Path+"%", comment.ID).
+		Order("depth desc").
+		Pluck("id", &idList).Error
+	return
+}
@@ -0,0 +1,70 @@
+package comment_ser
+
+import (
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/service/article_ser"
+	"gvb_server/service/redis_ser"
+)
+
+// AddArticleCount 文章评论数先写redis，由定时任务同步到文章
+// 写redis失败的文章记到数据库，由 ReconcileCount 按数据库重新计算，服务重启也不会漏
+func (CommentService) AddArticleCount(articleID string, delta int) {
+	if delta == 0 {
+		return
+	}
+	err := redis_ser.NewCommentCount().SetCount(articleID, delta)
+	if err != nil {
+		global.Log.Errorf("文章 %s 评论数 %d 写入redis失败 %s", articleID, delta, err)
+		err = global.DB.Where("article_id = ?", articleID).
+			FirstOrCreate(&models.CommentReconcileModel{ArticleID: articleID}).Error
+		if err != nil {
+			global.Log.Errorf("文章 %s 记录重新计算评论数失败 %s", articleID, err)
+		}
+	}
+}
+
+// ReconcileList 等待重新计算评论数的文章id
+func (CommentService) ReconcileList() (idList []string, err error) {
+	err = global.DB.Model(&models.CommentReconcileModel{}).Order("created_at").Pluck("article_id", &idList).Error
+	return idList, err
+}
+
+// ReconcileCount 按数据库重新计算文章评论数，减去redis里还没同步的增量后写回文章，返回处理的文章数
+// redis不可用时不处理，等下次
+func (s CommentService) ReconcileCount() (int, error) {
+	idList, err := s.ReconcileList()
+	if err != nil || len(idList) == 0 {
+		return 0, err
+	}
+	err = global.Redis.Ping().Err()
+	if err != nil {
+		return 0, err
+	}
+	repository := article_ser.NewRepository()
+	commentCount := redis_ser.NewCommentCount()
+	var count int
+	for _, articleID := range idList {
+		var total int64
+		err = global.DB.Model(&models.CommentModel{}).
+			Where("article_id = ? and status = ? and is_deleted = ?", articleID, ctype.CommentApproved, false).
+			Count(&total).Error
+		if err != nil {
+			return count, err
+		}
+		err = repository.Update(articleID, map[string]any{
+			"comment_count": int(total) - commentCount.Get(articleID),
+		})
+		if err != nil {
+			global.Log.Errorf("文章 %s 评论数重新计算失败 %s", articleID, err)
+			continue
+		}
+		err = global.DB.Delete(&models.CommentReconcileModel{}, "article_id = ?", articleID).Error
+		if err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
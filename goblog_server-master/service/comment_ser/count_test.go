@@ -0,0 +1,53 @@
+package comment_ser
+
+import (
+	"github.com/go-redis/redis"
+	"gvb_server/config"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/service/redis_ser"
+	"testing"
+)
+
+func TestReconcileCount(t *testing.T) {
+	db := setupModeration(t, config.Comment{})
+	s := CommentService{}
+	user := models.UserModel{NickName: "normal", Role: ctype.PermissionUser, TrustLevel: ctype.TrustNormal}
+	db.Create(&user)
+	db.Create(&models.ArticleModel{ID: "a1", Title: "a1"})
+	for _, content := range []string{"c1", "c2"} {
+		if err := s.Create(&models.CommentModel{ArticleID: "a1", Content: content, UserID: user.ID}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var comment models.CommentModel
+	db.Take(&comment, "content = ?", "c2")
+
+	// redis不可用，删除评论时文章评论数写入失败，记下来等待重新计算
+	client := global.Redis
+	global.Redis = redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+	if err := s.Remove(&comment, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := s.ReconcileList(); len(list) != 1 || list[0] != "a1" {
+		t.Fatalf("reconcile list: %v", list)
+	}
+	if _, err := s.ReconcileCount(); err == nil {
+		t.Fatal("redis down")
+	}
+
+	// redis恢复后按数据库重新计算，减去还没同步的增量
+	global.Redis = client
+	redis_ser.NewCommentCount().SetCount("a1", 1)
+	count, err := s.ReconcileCount()
+	if err != nil || count != 1 {
+		t.Fatalf("reconcile: %d %v", count, err)
+	}
+	var article models.ArticleModel
+	db.Take(&article, "id = ?", "a1")
+	list, _ := s.ReconcileList()
+	if article.CommentCount != 0 || len(list) != 0 {
+		t.Fatalf("comment count: %d %v", article.CommentCount, list)
+	}
+}
@@ -0,0 +1,181 @@
+package comment_ser
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"time"
+)
+
+// DeletedContent 删除后评论显示的内容
+const DeletedContent = "[deleted]"
+
+var (
+	ErrDeleted    = errors.New("评论已删除")
+	ErrNotDeleted = errors.New("评论没有被删除")
+)
+
+// counted 评论是否计入父评论的回复数和文章的评论数，通过审核并且没有删除的才算
+func counted(status ctype.CommentStatus, isDeleted bool) bool {
+	return status == ctype.CommentApproved && !isDeleted
+}
+
+// changeCount 评论是否计入发生变化时修改父评论的回复数，返回文章评论数的变化，文章评论数在事务提交后再写redis
+func changeCount(tx *gorm.DB, comment models.CommentModel, before, after bool) (delta int, err error) {
+	if before == after {
+		return 0, nil
+	}
+	delta = 1
+	if before {
+		delta = -1
+	}
+	if comment.ParentCommentID == nil {
+		return delta, nil
+	}
+	err = tx.Model(&models.CommentModel{}).
+		Where("id = ?", *comment.ParentCommentID).
+		Update("comment_count", gorm.Expr("comment_count + ?", delta)).Error
+	return delta, err
+}
+
+// CanEdit 是否还在可以编辑的时间内
+func (CommentService) CanEdit(comment models.CommentModel) bool {
+	window := time.Duration(global.Config.Comment.GetEditWindow()) * time.Second
+	return time.Since(comment.CreatedAt) <= window
+}
+
+// statusRank 审核状态的严重程度，越大越严
+var statusRank = map[ctype.CommentStatus]int{
+	ctype.CommentApproved: 0,
+	ctype.CommentPending:  1,
+	ctype.CommentRejected: 2,
+	ctype.CommentSpam:     3,
+}
+
+// editVerdict 编辑后的审核结果只能比原来的严，不能借编辑绕过审核
+// 已通过的可以被降为待审核或垃圾评论，待审核的最多保持待审核，已拒绝和垃圾评论保持不变
+func editVerdict(status ctype.CommentStatus, reason string, verdict Verdict) Verdict {
+	if statusRank[verdict.Status] > statusRank[status] {
+		return verdict
+	}
+	return Verdict{Status: status, Reason: reason}
+}
+
+// Edit 修改评论内容，之前的内容存到历史里，修改后的内容重新审核
+func (s CommentService) Edit(comment *models.CommentModel, userID uint, content string, verdict Verdict) error {
+	if comment.IsDeleted {
+		return ErrDeleted
+	}
+	before := counted(comment.Status, comment.IsDeleted)
+	verdict = editVerdict(comment.Status, comment.Reason, verdict)
+	now := time.Now()
+	var delta int
+	err := global.DB.Transaction(func(tx *gorm.DB) (err error) {
+		err = tx.Create(&models.CommentHistoryModel{
+			CommentID: comment.ID,
+			Content:   comment.Content,
+			Action:    models.CommentHistoryEdit,
+			UserID:    userID,
+		}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(comment).Updates(map[string]any{
+			"content":   content,
+			"status":    verdict.Status,
+			"reason":    verdict.Reason,
+			"edited_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		delta, err = changeCount(tx, *comment, before, counted(verdict.Status, false))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.AddArticleCount(comment.ArticleID, delta)
+	return nil
+}
+
+// Remove 软删除评论，内容替换为[deleted]，回复保留，原来的内容存到历史里用于恢复
+func (s CommentService) Remove(comment *models.CommentModel, userID uint) error {
+	if comment.IsDeleted {
+		return ErrDeleted
+	}
+	before := counted(comment.Status, comment.IsDeleted)
+	var delta int
+	err := global.DB.Transaction(func(tx *gorm.DB) (err error) {
+		err = tx.Create(&models.CommentHistoryModel{
+			CommentID: comment.ID,
+			Content:   comment.Content,
+			Action:    models.CommentHistoryDelete,
+			UserID:    userID,
+		}).Error
+		if err != nil {
+			return err
+		}
+		// 带上删除状态做条件更新，并发删除时只有一个请求能改到这一行
+		result := tx.Model(comment).Where("is_deleted = ?", false).Updates(map[string]any{
+			"content":    DeletedContent,
+			"is_deleted": true,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return ErrDeleted
+		}
+		delta, err = changeCount(tx, *comment, before, false)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.AddArticleCount(comment.ArticleID, delta)
+	return nil
+}
+
+// Restore 恢复删除的评论，内容取最后一次删除前的内容
+func (s CommentService) Restore(comment *models.CommentModel) error {
+	if !comment.IsDeleted {
+		return ErrNotDeleted
+	}
+	var history models.CommentHistoryModel
+	err := global.DB.
+		Where("comment_id = ? and action = ?", comment.ID, models.CommentHistoryDelete).
+		Order("id desc").
+		Take(&history).Error
+	if err != nil {
+		return err
+	}
+	var delta int
+	err = global.DB.Transaction(func(tx *gorm.DB) (err error) {
+		result := tx.Model(comment).Where("is_deleted = ?", true).Updates(map[string]any{
+			"content":    history.Content,
+			"is_deleted": false,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return ErrNotDeleted
+		}
+		delta, err = changeCount(tx, *comment, false, counted(comment.Status, false))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.AddArticleCount(comment.ArticleID, delta)
+	return nil
+}
+
+// HistoryList 评论的历史版本，新的在前
+func (CommentService) HistoryList(commentID uint) (list []models.CommentHistoryModel, err error) {
+	err = global.DB.Where("comment_id = ?", commentID).Order("id desc").Find(&list).Error
+	return
+}
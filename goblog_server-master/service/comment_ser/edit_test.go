@@ -0,0 +1,127 @@
+package comment_ser
+
+import (
+	"gvb_server/config"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"testing"
+	"time"
+)
+
+func TestEditRemoveRestore(t *testing.T) {
+	db := setupModeration(t, config.Comment{Moderation: true, SensitiveWords: []string{"赌博"}})
+	ReloadSensitiveWords()
+
+	s := CommentService{}
+	user := models.UserModel{NickName: "normal", Role: ctype.PermissionUser, TrustLevel: ctype.TrustNormal}
+	db.Create(&user)
+	root := &models.CommentModel{ArticleID: "a1", Content: "root", UserID: user.ID}
+	reply := &models.CommentModel{ArticleID: "a1", Content: "reply", UserID: user.ID}
+	if err := s.Create(root, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Create(reply, root); err != nil {
+		t.Fatal(err)
+	}
+	commentCount := func() int {
+		var comment models.CommentModel
+		db.Take(&comment, root.ID)
+		return comment.CommentCount
+	}
+	if !s.CanEdit(*reply) || s.CanEdit(models.CommentModel{MODEL: models.MODEL{CreatedAt: time.Now().Add(-time.Hour)}}) {
+		t.Fatal("edit window")
+	}
+
+	// 编辑后命中敏感词进入待审核，不再计数
+	content := "一起来赌博"
+	err := s.Edit(reply, user.ID, content, s.Moderate(user, content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := commentCount(); n != 0 {
+		t.Fatalf("pending edit: %d", n)
+	}
+	s.SetStatus([]uint{reply.ID}, ctype.CommentApproved)
+	if n := commentCount(); n != 1 {
+		t.Fatalf("approved edit: %d", n)
+	}
+
+	// 删除根评论，回复保留
+	if err = s.Remove(root, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	list, count, _ := s.RootList("a1", models.PageInfo{Page: 1, Limit: 10}, 10)
+	if count != 1 || list[0].Content != DeletedContent || !list[0].IsDeleted || len(list[0].SubComments) != 1 {
+		t.Fatalf("removed root: %+v", list)
+	}
+	if s.Remove(root, user.ID) != ErrDeleted {
+		t.Fatal("remove twice")
+	}
+
+	// 删除回复，父评论回复数-1，恢复后+1
+	db.Take(reply, reply.ID)
+	stale := *reply
+	if err = s.Remove(reply, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n := commentCount(); n != 0 {
+		t.Fatalf("removed reply: %d", n)
+	}
+	// 并发请求读到的是删除前的评论，条件更新不会重复扣减
+	if s.Remove(&stale, user.ID) != ErrDeleted || commentCount() != 0 {
+		t.Fatal("remove stale comment")
+	}
+	// 删除的评论审核状态变化不影响计数
+	s.SetStatus([]uint{reply.ID}, ctype.CommentRejected)
+	s.SetStatus([]uint{reply.ID}, ctype.CommentApproved)
+	if n := commentCount(); n != 0 {
+		t.Fatalf("removed reply status: %d", n)
+	}
+	db.Take(reply, reply.ID)
+	stale = *reply
+	if err = s.Restore(reply); err != nil {
+		t.Fatal(err)
+	}
+	db.Take(reply, reply.ID)
+	if n := commentCount(); n != 1 || reply.Content != content || reply.IsDeleted {
+		t.Fatalf("restored: %d %+v", n, reply)
+	}
+	if s.Restore(&stale) != ErrNotDeleted || commentCount() != 1 {
+		t.Fatal("restore stale comment")
+	}
+
+	historyList, _ := s.HistoryList(reply.ID)
+	if len(historyList) != 2 || historyList[0].Action != models.CommentHistoryDelete ||
+		historyList[1].Action != models.CommentHistoryEdit || historyList[1].Content != "reply" {
+		t.Fatalf("history: %+v", historyList)
+	}
+}
+
+func TestEditVerdict(t *testing.T) {
+	approved := Verdict{Status: ctype.CommentApproved}
+	pending := Verdict{Status: ctype.CommentPending, Reason: "包含敏感词"}
+	spam := Verdict{Status: ctype.CommentSpam, Reason: "链接过多"}
+	list := []struct {
+		status  ctype.CommentStatus
+		verdict Verdict
+		want    ctype.CommentStatus
+	}{
+		{ctype.CommentApproved, approved, ctype.CommentApproved},
+		{ctype.CommentApproved, pending, ctype.CommentPending},
+		{ctype.CommentApproved, spam, ctype.CommentSpam},
+		{ctype.CommentPending, approved, ctype.CommentPending},
+		{ctype.CommentPending, spam, ctype.CommentSpam},
+		{ctype.CommentRejected, approved, ctype.CommentRejected},
+		{ctype.CommentRejected, pending, ctype.CommentRejected},
+		{ctype.CommentSpam, approved, ctype.CommentSpam},
+	}
+	for _, v := range list {
+		got := editVerdict(v.status, "原因", v.verdict)
+		if got.Status != v.want {
+			t.Fatalf("%s + %s: %s", v.status, v.verdict.Status, got.Status)
+		}
+		if got.Status == v.status && got.Reason != "原因" {
+			t.Fatalf("reason: %+v", got)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+package comment_ser
+
+type CommentService struct {
+}
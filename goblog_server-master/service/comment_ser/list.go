@@ -0,0 +1,140 @@
+package comment_ser
+
+import (
+	"gorm.io/gorm"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+)
+
+// 根评论支持的排序
+var rootSortMap = map[string]string{
+	"":                "created_at desc",
+	"created_at desc": "created_at desc",
+	"created_at asc":  "created_at asc",
+	"digg_count desc": "digg_count desc, created_at desc",
+}
+
+// RootList 文章的根评论分页，每条根评论带上前replyLimit条回复
+// 根评论一次查询，这一页所有根评论的回复再一次查询，在内存里组装成树
+func (CommentService) RootList(articleID string, page models.PageInfo, replyLimit int) (list []*models.CommentModel, count int64, err error) {
+	query := global.DB.Model(&models.CommentModel{}).Where("article_id = ? and parent_comment_id is null and status = ?", articleID, ctype.CommentApproved)
+	err = query.Session(&gorm.Session{}).Count(&count).Error
+	if err != nil || count == 0 {
+		return nil, count, err
+	}
+	sort, ok := rootSortMap[page.Sort]
+	if !ok {
+		sort = rootSortMap[""]
+	}
+	offset := (page.Page - 1) * page.Limit
+	if offset < 0 {
+		offset = 0
+	}
+	err = query.Preload("User").Order(sort).Limit(page.Limit).Offset(offset).Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return list, count, err
+	}
+
+	var rootIDList []uint
+	var rootMap = map[uint]*models.CommentModel{}
+	for _, root := range list {
+		rootIDList = append(rootIDList, root.ID)
+		rootMap[root.ID] = root
+	}
+	// 每个线程只取前replyLimit+1条，多取的一条用来判断还有没有更多，剩下的用游标加载
+	threadQuery := global.DB.Model(&models.CommentModel{}).
+		Select("*, row_number() over (partition by root_id order by path) as thread_row").
+		Where("root_id in ? and parent_comment_id is not null and status = ?", rootIDList, ctype.CommentApproved)
+	var replyList []*models.CommentModel
+	err = global.DB.Preload("User").
+		Table("(?) as thread", threadQuery).
+		Where("thread_row <= ?", replyLimit+1).
+		Order("root_id, path").
+		Find(&replyList).Error
+	if err != nil {
+		return list, count, err
+	}
+
+	var threadMap = map[uint][]*models.CommentModel{}
+	var moreMap = map[uint]bool{}
+	for _, reply := range replyList {
+		thread := threadMap[reply.RootID]
+		if len(thread) >= replyLimit {
+			moreMap[reply.RootID] = true
+			continue
+		}
+		threadMap[reply.RootID] = append(thread, reply)
+	}
+	for rootID, root := range rootMap {
+		thread := threadMap[rootID]
+		// 父评论没有通过审核的回复挂到根评论下，和回复数保持一致
+		topList := BuildTree(append([]*models.CommentModel{root}, thread...))
+		setDisplayParent(topList[1:], rootID)
+		root.SubComments = append(root.SubComments, topList[1:]...)
+		if !moreMap[rootID] {
+			continue
+		}
+		// 一条回复都没带的，从根评论开始加载
+		root.ReplyCursor = root.Path
+		if len(thread) > 0 {
+			root.ReplyCursor = thread[len(thread)-1].Path
+		}
+	}
+	return list, count, nil
+}
+
+// ReplyList 加载更多回复，cursor为上一页最后一条回复的游标，没有更多时返回的游标为空
+// 和RootList一样，父评论没有通过审核的回复挂到根评论下，顶层回复的display_parent_id是前端要挂到的评论
+func (CommentService) ReplyList(rootID uint, cursor string, limit int) (list []*models.CommentModel, nextCursor string, err error) {
+	var replyList []*models.CommentModel
+	err = global.DB.Preload("User").
+		Where("root_id = ? and parent_comment_id is not null and path > ? and status = ?", rootID, cursor, ctype.CommentApproved).
+		Order("path").
+		Limit(limit + 1).
+		Find(&replyList).Error
+	if err != nil {
+		return nil, "", err
+	}
+	if len(replyList) > limit {
+		replyList = replyList[:limit]
+		nextCursor = replyList[limit-1].Path
+	}
+	list = BuildTree(replyList)
+
+	// 父评论不在这一页的，看父评论是否通过审核，没通过的挂到根评论下
+	var parentIDList []uint
+	for _, reply := range list {
+		parentIDList = append(parentIDList, *reply.ParentCommentID)
+	}
+	var visibleIDList []uint
+	if len(parentIDList) > 0 {
+		err = global.DB.Model(&models.CommentModel{}).
+			Where("id in ? and status = ?", parentIDList, ctype.CommentApproved).
+			Pluck("id", &visibleIDList).Error
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	var visibleMap = map[uint]bool{}
+	for _, id := range visibleIDList {
+		visibleMap[id] = true
+	}
+	var orphanList []*models.CommentModel
+	for _, reply := range list {
+		if visibleMap[*reply.ParentCommentID] {
+			reply.DisplayParentID = *reply.ParentCommentID
+			continue
+		}
+		orphanList = append(orphanList, reply)
+	}
+	setDisplayParent(orphanList, rootID)
+	return list, nextCursor, nil
+}
+
+// setDisplayParent 父评论没有通过审核的回复，显示在根评论下
+func setDisplayParent(orphanList []*models.CommentModel, rootID uint) {
+	for _, reply := range orphanList {
+		reply.DisplayParentID = rootID
+	}
+}
@@ -0,0 +1,92 @@
+package comment_ser
+
+import (
+	"gvb_server/internal/testdb"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"testing"
+)
+
+func TestRootListAndReplyList(t *testing.T) {
+	db := testdb.Open(t, &models.UserModel{}, &models.CommentModel{})
+
+	s := CommentService{}
+	create := func(parent *models.CommentModel) *models.CommentModel {
+		comment := &models.CommentModel{ArticleID: "a1", Content: "c"}
+		err := s.Create(comment, parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return comment
+	}
+	// root1 -> r1 -> r11, root1 -> r2, root2
+	root1 := create(nil)
+	r1 := create(root1)
+	r11 := create(r1)
+	r2 := create(root1)
+	root2 := create(nil)
+
+	list, count, err := s.RootList("a1", models.PageInfo{Page: 1, Limit: 10, Sort: "created_at asc"}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || len(list) != 2 || list[0].ID != root1.ID || list[1].ID != root2.ID {
+		t.Fatalf("roots: %d %+v", count, list)
+	}
+	// 只带了r1和r11两条回复
+	if len(list[0].SubComments) != 1 || list[0].SubComments[0].ID != r1.ID ||
+		len(list[0].SubComments[0].SubComments) != 1 || list[0].SubComments[0].SubComments[0].ID != r11.ID {
+		t.Fatalf("thread: %+v", list[0].SubComments)
+	}
+	if list[0].ReplyCursor == "" || list[1].ReplyCursor != "" {
+		t.Fatalf("cursor: %q %q", list[0].ReplyCursor, list[1].ReplyCursor)
+	}
+
+	replyList, cursor, err := s.ReplyList(root1.ID, list[0].ReplyCursor, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replyList) != 1 || replyList[0].ID != r2.ID || replyList[0].DisplayParentID != root1.ID || cursor != "" {
+		t.Fatalf("more: %+v %q", replyList, cursor)
+	}
+
+	var parent models.CommentModel
+	db.Take(&parent, root1.ID)
+	if parent.CommentCount != 2 {
+		t.Fatalf("root comment_count %d", parent.CommentCount)
+	}
+	idList, _ := s.SubIDList(*root1)
+	if len(idList) != 3 || idList[0] != r11.ID {
+		t.Fatalf("sub id list %v", idList)
+	}
+}
+
+func TestRootListOrphanReply(t *testing.T) {
+	testdb.Open(t, &models.UserModel{}, &models.CommentModel{})
+
+	s := CommentService{}
+	// root -> pending -> orphan，待审核的回复下有一条已通过的回复
+	root := &models.CommentModel{ArticleID: "a1", Content: "root"}
+	pending := &models.CommentModel{ArticleID: "a1", Content: "pending", Status: ctype.CommentPending}
+	orphan := &models.CommentModel{ArticleID: "a1", Content: "orphan"}
+	for _, c := range [][2]*models.CommentModel{{root, nil}, {pending, root}, {orphan, pending}} {
+		if err := s.Create(c[0], c[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list, _, err := s.RootList("a1", models.PageInfo{Page: 1, Limit: 10}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || len(list[0].SubComments) != 1 || list[0].SubComments[0].ID != orphan.ID || list[0].ReplyCursor != "" {
+		t.Fatalf("orphan: %+v", list)
+	}
+	// 加载更多回复时同样挂到根评论下
+	replyList, _, err := s.ReplyList(root.ID, root.Path, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replyList) != 1 || replyList[0].ID != orphan.ID || replyList[0].DisplayParentID != root.ID {
+		t.Fatalf("orphan reply list: %+v", replyList)
+	}
+}
@@ -0,0 +1,207 @@
+package comment_ser
+
+import (
+	"bufio"
+	"gorm.io/gorm"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/service/notification_ser"
+	"gvb_server/service/redis_ser"
+	"gvb_server/utils/ahocorasick"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Verdict 审核结果
+type Verdict struct {
+	Status ctype.CommentStatus
+	Reason string
+}
+
+var linkRegexp = regexp.MustCompile(`(?i)https?://|www\.`)
+
+// 敏感词匹配器，配置修改后重新加载
+var sensitive struct {
+	sync.Mutex
+	matcher *ahocorasick.Matcher
+}
+
+// ReloadSensitiveWords 重新加载敏感词，修改配置后调用
+func ReloadSensitiveWords() {
+	conf := global.Config.Comment
+	wordList := append([]string{}, conf.SensitiveWords...)
+	if conf.SensitiveFile != "" {
+		file, err := os.Open(conf.SensitiveFile)
+		if err != nil {
+			global.Log.Errorf("读取敏感词文件失败 %s", err)
+		} else {
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				wordList = append(wordList, scanner.Text())
+			}
+			file.Close()
+		}
+	}
+	matcher := ahocorasick.New(wordList)
+	sensitive.Lock()
+	sensitive.matcher = matcher
+	sensitive.Unlock()
+}
+
+func sensitiveMatcher() *ahocorasick.Matcher {
+	sensitive.Lock()
+	matcher := sensitive.matcher
+	sensitive.Unlock()
+	if matcher != nil {
+		return matcher
+	}
+	ReloadSensitiveWords()
+	sensitive.Lock()
+	defer sensitive.Unlock()
+	return sensitive.matcher
+}
+
+// AllowComment 频率限制，管理员不限制
+func (CommentService) AllowComment(user models.UserModel) (bool, error) {
+	if user.Role == ctype.PermissionAdmin {
+		return true, nil
+	}
+	return redis_ser.AllowComment(user.ID, global.Config.Comment.GetRateLimit())
+}
+
+// Moderate 评论审核
+// 链接过多、短时间内重复的内容直接算垃圾评论；信任用户跳过审核；命中敏感词和新用户的评论进入待审核
+func (CommentService) Moderate(user models.UserModel, content string) Verdict {
+	approved := Verdict{Status: ctype.CommentApproved}
+	conf := global.Config.Comment
+	if !conf.Moderation || user.Role == ctype.PermissionAdmin {
+		return approved
+	}
+	if len(linkRegexp.FindAllStringIndex(content, -1)) > conf.GetMaxLinks() {
+		return Verdict{Status: ctype.CommentSpam, Reason: "链接过多"}
+	}
+	if isRepeat(user.ID, content, conf.GetRepeatWindow()) {
+		return Verdict{Status: ctype.CommentSpam, Reason: "重复内容"}
+	}
+	if user.TrustLevel >= ctype.TrustTrusted {
+		return approved
+	}
+	if wordList := sensitiveMatcher().Match(content); len(wordList) > 0 {
+		reason := []rune("敏感词：" + strings.Join(wordList, "、"))
+		if len(reason) > 64 {
+			reason = reason[:64]
+		}
+		return Verdict{Status: ctype.CommentPending, Reason: string(reason)}
+	}
+	if user.TrustLevel == ctype.TrustNew {
+		return Verdict{Status: ctype.CommentPending, Reason: "新用户"}
+	}
+	return approved
+}
+
+// isRepeat 用户在window秒内是否发过相同的内容
+func isRepeat(userID uint, content string, window int) bool {
+	var count int64
+	global.DB.Model(&models.CommentModel{}).
+		Where("user_id = ? and content = ? and created_at >= ?", userID, content, time.Now().Add(-time.Duration(window)*time.Second)).
+		Count(&count)
+	return count > 0
+}
+
+// SetStatus 批量修改审核状态，返回实际修改的数量
+// 评论从不计入变为计入时父评论和文章的评论数+1，反之-1，已删除的评论不计入
+func (s CommentService) SetStatus(idList []uint, status ctype.CommentStatus) (count int, err error) {
+	var list []models.CommentModel
+	err = global.DB.Find(&list, "id in ?", idList).Error
+	if err != nil {
+		return 0, err
+	}
+	var articleDelta = map[string]int{}
+	var userIDList []uint
+	var approvedList, rejectedList []models.CommentModel
+	err = global.DB.Transaction(func(tx *gorm.DB) error {
+		for _, comment := range list {
+			if comment.Status == status {
+				continue
+			}
+			before := counted(comment.Status, comment.IsDeleted)
+			// 带上原状态做条件更新，并发审核时只有一个请求改到这一行，避免重复计数和通知
+			result := tx.Model(&models.CommentModel{}).
+				Where("id = ? and status = ?", comment.ID, comment.Status).
+				Update("status", status)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected != 1 {
+				continue
+			}
+			if comment.Status == ctype.CommentPending {
+				switch status {
+				case ctype.CommentApproved:
+					approvedList = append(approvedList, comment)
+				case ctype.CommentRejected:
+					rejectedList = append(rejectedList, comment)
+				}
+			}
+			count++
+			if status == ctype.CommentApproved {
+				userIDList = append(userIDList, comment.UserID)
+			}
+			delta, err := changeCount(tx, comment, before, counted(status, comment.IsDeleted))
+			if err != nil {
+				return err
+			}
+			articleDelta[comment.ArticleID] += delta
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for articleID, delta := range articleDelta {
+		s.AddArticleCount(articleID, delta)
+	}
+	promoteUsers(userIDList)
+	notifyModeration(approvedList, rejectedList)
+	return count, nil
+}
+
+// notifyModeration 待审核的评论通过后才通知被回复和被@的人，被拒绝的通知评论人
+func notifyModeration(approvedList, rejectedList []models.CommentModel) {
+	notificationService := notification_ser.NotificationService{}
+	for _, comment := range approvedList {
+		err := notificationService.Comment(comment)
+		if err != nil {
+			global.Log.Error(err)
+		}
+	}
+	for _, comment := range rejectedList {
+		err := notificationService.System(comment.UserID, "你的评论没有通过审核", comment.Content)
+		if err != nil {
+			global.Log.Error(err)
+		}
+	}
+}
+
+// promoteUsers 通过审核的评论数达到配置的数量后，新用户升为普通用户
+func promoteUsers(userIDList []uint) {
+	if len(userIDList) == 0 {
+		return
+	}
+	var promoteIDList []uint
+	global.DB.Model(&models.CommentModel{}).
+		Joins("join user_models on user_models.id = comment_models.user_id").
+		Where("comment_models.user_id in ? and comment_models.status = ? and user_models.trust_level = ?",
+			userIDList, ctype.CommentApproved, ctype.TrustNew).
+		Group("comment_models.user_id").
+		Having("count(*) >= ?", global.Config.Comment.GetTrustApproved()).
+		Pluck("comment_models.user_id", &promoteIDList)
+	if len(promoteIDList) == 0 {
+		return
+	}
+	global.DB.Model(&models.UserModel{}).Where("id in ?", promoteIDList).Update("trust_level", ctype.TrustNormal)
+}
@@ -0,0 +1,116 @@
+package comment_ser
+
+import (
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"gorm.io/gorm"
+	"gvb_server/config"
+	"gvb_server/global"
+	"gvb_server/internal/testdb"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/service/article_ser"
+	"gvb_server/service/redis_ser"
+	"testing"
+)
+
+// setupModeration 内存数据库和内存redis
+func setupModeration(t *testing.T, conf config.Comment) *gorm.DB {
+	db := testdb.Open(t, &models.ArticleModel{}, &models.ArticleTagModel{}, &models.UserModel{},
+		&models.CommentModel{}, &models.CommentHistoryModel{}, &models.NotificationModel{},
+		&models.CommentReconcileModel{})
+	mr := miniredis.RunT(t)
+	global.Redis = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	global.Config = &config.Config{Comment: conf, System: config.System{ArticleStorage: article_ser.StorageMysql}}
+	return db
+}
+
+func TestModerateAndSetStatus(t *testing.T) {
+	db := setupModeration(t, config.Comment{
+		Moderation:     true,
+		SensitiveWords: []string{"赌博"},
+		TrustApproved:  2,
+	})
+	ReloadSensitiveWords()
+
+	s := CommentService{}
+	newUser := models.UserModel{NickName: "new", Role: ctype.PermissionUser, TrustLevel: ctype.TrustNew}
+	normal := models.UserModel{NickName: "normal", Role: ctype.PermissionUser, TrustLevel: ctype.TrustNormal}
+	trusted := models.UserModel{NickName: "trusted", Role: ctype.PermissionUser, TrustLevel: ctype.TrustTrusted}
+	for _, user := range []*models.UserModel{&newUser, &normal, &trusted} {
+		db.Create(user)
+	}
+
+	verdictList := []struct {
+		user    models.UserModel
+		content string
+		status  ctype.CommentStatus
+	}{
+		{normal, "正常的评论", ctype.CommentApproved},
+		{normal, "一起来赌博", ctype.CommentPending},
+		{trusted, "一起来赌博", ctype.CommentApproved},
+		{newUser, "正常的评论", ctype.CommentPending},
+		{trusted, "http://a.com https://b.com www.c.com", ctype.CommentSpam},
+	}
+	for _, v := range verdictList {
+		verdict := s.Moderate(v.user, v.content)
+		if verdict.Status != v.status {
+			t.Fatalf("%s %q: %s %s", v.user.NickName, v.content, verdict.Status, verdict.Reason)
+		}
+	}
+
+	root := &models.CommentModel{ArticleID: "a1", Content: "root", UserID: normal.ID}
+	if err := s.Create(root, nil); err != nil {
+		t.Fatal(err)
+	}
+	// 重复内容
+	if verdict := s.Moderate(normal, "root"); verdict.Status != ctype.CommentSpam {
+		t.Fatalf("repeat: %s", verdict.Status)
+	}
+
+	var pendingList []*models.CommentModel
+	for i := 0; i < 2; i++ {
+		comment := &models.CommentModel{ArticleID: "a1", Content: "reply", UserID: newUser.ID, Status: ctype.CommentPending}
+		if err := s.Create(comment, root); err != nil {
+			t.Fatal(err)
+		}
+		pendingList = append(pendingList, comment)
+	}
+	commentCount := func() int {
+		var comment models.CommentModel
+		db.Take(&comment, root.ID)
+		return comment.CommentCount
+	}
+	if n := commentCount(); n != 0 {
+		t.Fatalf("pending counted: %d", n)
+	}
+
+	count, err := s.SetStatus([]uint{pendingList[0].ID, pendingList[1].ID}, ctype.CommentApproved)
+	if err != nil || count != 2 {
+		t.Fatalf("approve: %d %v", count, err)
+	}
+	if n := commentCount(); n != 2 {
+		t.Fatalf("approved count: %d", n)
+	}
+	if n := redis_ser.NewCommentCount().Get("a1"); n != 2 {
+		t.Fatalf("article count: %d", n)
+	}
+	// 通过审核的评论数达到配置，新用户升为普通用户
+	db.Take(&newUser, newUser.ID)
+	if newUser.TrustLevel != ctype.TrustNormal {
+		t.Fatalf("trust level: %d", newUser.TrustLevel)
+	}
+
+	count, _ = s.SetStatus([]uint{pendingList[0].ID}, ctype.CommentSpam)
+	if n := commentCount(); count != 1 || n != 1 {
+		t.Fatalf("spam: %d %d", count, n)
+	}
+	if n := redis_ser.NewCommentCount().Get("a1"); n != 1 {
+		t.Fatalf("spam article count: %d", n)
+	}
+	// 状态不变的不重复计数
+	count, _ = s.SetStatus([]uint{pendingList[1].ID}, ctype.CommentApproved)
+	if n := commentCount(); count != 0 || n != 1 {
+		t.Fatalf("unchanged: %d %d", count, n)
+	}
+}
@@ -0,0 +1,41 @@
+package comment_ser
+
+import (
+	"fmt"
+	"gvb_server/models"
+)
+
+// 路径里每一级id的宽度，补0后按字符串排序和按id排序一致
+const pathIDWidth = 10
+
+// MaxDepth 最多的回复层级，受路径字段长度限制
+const MaxDepth = 40
+
+// PathSegment 路径里的一级
+func PathSegment(id uint) string {
+	return fmt.Sprintf("%0*d/", pathIDWidth, id)
+}
+
+// BuildTree 把按路径排好序的评论组装成树，父评论不在列表里的作为顶层返回
+func BuildTree(list []*models.CommentModel) (topList []*models.CommentModel) {
+	var nodeMap = map[uint]*models.CommentModel{}
+	for _, model := range list {
+		nodeMap[model.ID] = model
+		if model.ParentCommentID != nil {
+			if parent, ok := nodeMap[*model.ParentCommentID]; ok {
+				parent.SubComments = append(parent.SubComments, model)
+				continue
+			}
+		}
+		topList = append(topList, model)
+	}
+	return topList
+}
+
+// Walk 先序遍历评论树
+func Walk(list []*models.CommentModel, fn func(model *models.CommentModel)) {
+	for _, model := range list {
+		fn(model)
+		Walk(model.SubComments, fn)
+	}
+}
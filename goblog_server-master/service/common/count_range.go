@@ -0,0 +1,50 @@
+package common
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// ComCountByRange 按区间统计数量，boundList相邻的两个值是一个区间，包含起点不包含终点，返回每个区间的数量
+// 一条sql里每个区间一个 count(case when)，只查计数不查出每一行，不依赖数据库的日期函数
+func ComCountByRange(query *gorm.DB, column string, boundList []any) ([]int, error) {
+	if len(boundList) < 2 {
+		return nil, nil
+	}
+	n := len(boundList) - 1
+	var selectList []string
+	var args []any
+	for i := 0; i < n; i++ {
+		selectList = append(selectList, fmt.Sprintf("count(case when %s >= ? and %s < ? then 1 end)", column, column))
+		args = append(args, boundList[i], boundList[i+1])
+	}
+	rows, err := query.
+		Select(strings.Join(selectList, ", "), args...).
+		Where(fmt.Sprintf("%s >= ? and %s < ?", column, column), boundList[0], boundList[n]).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var countList = make([]int64, n)
+	var dest = make([]any, n)
+	for i := range countList {
+		dest[i] = &countList[i]
+	}
+	if rows.Next() {
+		err = rows.Scan(dest...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	var list = make([]int, n)
+	for i, count := range countList {
+		list[i] = int(count)
+	}
+	return list, nil
+}
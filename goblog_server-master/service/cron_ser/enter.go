@@ -1,17 +1,37 @@
 package cron_ser
 
 import (
+	"fmt"
 	"github.com/robfig/cron/v3"
+	"gvb_server/global"
+	"gvb_server/service/redis_ser"
 	"time"
 )
 
 // CronInit 定时任务
 func CronInit() {
 
+	// 升级前redis里的计数没有记录变化的id，启动时全部标记一次
+	for _, countDB := range []redis_ser.CountDB{
+		redis_ser.NewDigg(),
+		redis_ser.NewArticleLook(),
+		redis_ser.NewCommentCount(),
+		redis_ser.NewCommentDigg(),
+	} {
+		err := countDB.MarkAllDirty()
+		if err != nil {
+			global.Log.Error(err)
+		}
+	}
+
 	timezone, _ := time.LoadLocation("Asia/Shanghai")
 	Cron := cron.New(cron.WithSeconds(), cron.WithLocation(timezone))
 	Cron.AddFunc("*/10 * * * * *", SyncArticleData)
 	Cron.AddFunc("*/10 * * * * *", SyncCommentData)
+	Cron.AddFunc("30 * * * * *", ReconcileCommentCount)
+	Cron.AddFunc("0 * * * * *", PublishScheduledArticles)
+	// 发送时间在启动时读取，修改后重启生效
+	Cron.AddFunc(fmt.Sprintf("0 0 %d * * *", global.Config.Notification.GetDigestHour()), SendNotificationDigest)
 	Cron.Start()
 
 }
@@ -0,0 +1,8 @@
+package cron_ser
+
+import "gvb_server/service/notification_ser"
+
+// SendNotificationDigest 每天发一次未读通知的邮件摘要
+func SendNotificationDigest() {
+	notification_ser.NotificationService{}.SendDigest()
+}
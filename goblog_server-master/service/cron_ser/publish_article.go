@@ -0,0 +1,36 @@
+package cron_ser
+
+import (
+	"gvb_server/global"
+	"gvb_server/models/ctype"
+	"gvb_server/service/article_ser"
+	"gvb_server/service/es_ser"
+	"gvb_server/service/redis_ser"
+	"time"
+)
+
+// PublishScheduledArticles 发布到达发布时间的待发布文章
+func PublishScheduledArticles() {
+	repository := article_ser.NewRepository()
+	list, err := repository.ListScheduled(time.Now())
+	if err != nil {
+		global.Log.Error(err)
+		return
+	}
+
+	for _, article := range list {
+		err = repository.Update(article.ID, map[string]any{
+			"status": int(ctype.ArticlePublished),
+		})
+		if err != nil {
+			global.Log.Error(err)
+			continue
+		}
+		// 发布后同步到全文搜索
+		es_ser.AsyncArticleByFullText(article.ID, article.Title, article.Content)
+		global.Log.Infof("%s 定时发布成功", article.Title)
+	}
+	if len(list) > 0 {
+		redis_ser.ClearSitemap()
+	}
+}
@@ -1,72 +1,92 @@
 package cron_ser
 
 import (
-	"context"
-	"encoding/json"
-	"github.com/olivere/elastic/v7"
 	"gvb_server/global"
-	"gvb_server/models"
+	"gvb_server/service/article_ser"
 	"gvb_server/service/redis_ser"
 )
 
+// 每批同步的文章数
+const syncBatchSize = 1000
+
 // SyncArticleData 同步redis文章数据到es
+// 只处理有变化的文章，redis里的计数取出后清零，按增量批量更新，同步失败的加回redis
 func SyncArticleData() {
-	// 1.查询es中的全部数据，为后面数据更新做准备
-	result, err := global.ESClient.
-		Search(models.ArticleModel{}.Index()).
-		Query(elastic.NewMatchAllQuery()).
-		Size(10000).
-		Do(context.Background())
-	if err != nil {
-		global.Log.Error(err)
-		return
-	}
-	// 2.拿到redis中的缓存数据
-	diggInfo := redis_ser.NewDigg().GetInfo()
-	lookInfo := redis_ser.NewArticleLook().GetInfo()
-	commentInfo := redis_ser.NewCommentCount().GetInfo()
+	repository := article_ser.NewRepository()
+	digg := redis_ser.NewDigg()
+	look := redis_ser.NewArticleLook()
+	comment := redis_ser.NewCommentCount()
 
-	for _, hit := range result.Hits.Hits {
-		var article models.ArticleModel
-		err = json.Unmarshal(hit.Source, &article)
+	for {
+		// 1.取出一批有变化的计数
+		diggInfo, err := digg.TakeDirty(syncBatchSize)
 		if err != nil {
 			global.Log.Error(err)
-			continue
+			return
+		}
+		lookInfo, err := look.TakeDirty(syncBatchSize)
+		if err != nil {
+			global.Log.Error(err)
+			restoreCount(digg, diggInfo)
+			return
+		}
+		commentInfo, err := comment.TakeDirty(syncBatchSize)
+		if err != nil {
+			global.Log.Error(err)
+			restoreCount(digg, diggInfo)
+			restoreCount(look, lookInfo)
+			return
+		}
+		if len(diggInfo) == 0 && len(lookInfo) == 0 && len(commentInfo) == 0 {
+			return
 		}
-		digg := diggInfo[hit.Id]
-		look := lookInfo[hit.Id]
-		comment := commentInfo[hit.Id]
-		// 3.计算新的数据 旧数据+缓存中数据
-		newDigg := article.DiggCount + digg
-		newLook := article.LookCount + look
-		newComment := article.CommentCount + comment
 
-		// 4.判断数据量是否有变化，如果三个变化之后的数据和之前一样
-		if digg == 0 && look == 0 && comment == 0 {
-			//global.Log.Infof("%s 无变化", article.Title)
-			continue
+		// 2.合并成每篇文章的增量
+		deltaMap := map[string]article_ser.CountDelta{}
+		for id, num := range diggInfo {
+			delta := deltaMap[id]
+			delta.DiggCount = num
+			deltaMap[id] = delta
+		}
+		for id, num := range lookInfo {
+			delta := deltaMap[id]
+			delta.LookCount = num
+			deltaMap[id] = delta
+		}
+		for id, num := range commentInfo {
+			delta := deltaMap[id]
+			delta.CommentCount = num
+			deltaMap[id] = delta
 		}
 
-		// 5.更新
-		_, err := global.ESClient.Update().
-			Index(models.ArticleModel{}.Index()).
-			Id(hit.Id).
-			Doc(map[string]int{
-				"digg_count":    newDigg,
-				"look_count":    newLook,
-				"comment_count": newComment,
-			}).Do(context.Background())
+		// 3.批量更新，失败的加回redis等下次同步
+		failedIDList, err := repository.AddCounts(deltaMap)
 		if err != nil {
 			global.Log.Error(err)
-			continue
 		}
-		global.Log.Infof("%s 更新成功 点赞数：%d,评论数：%d,浏览量：%d",
-			article.Title, newDigg, newComment, newLook)
+		for _, id := range failedIDList {
+			delta := deltaMap[id]
+			restoreCount(digg, map[string]int{id: delta.DiggCount})
+			restoreCount(look, map[string]int{id: delta.LookCount})
+			restoreCount(comment, map[string]int{id: delta.CommentCount})
+		}
+		global.Log.Infof("同步文章数据 %d 篇，失败 %d 篇", len(deltaMap), len(failedIDList))
+		// 有失败的等下次定时任务，避免同一批反复重试
+		if err != nil || len(failedIDList) > 0 {
+			return
+		}
 	}
+}
 
-	// 6.清除redis中的数据
-	redis_ser.NewDigg().Clear()
-	redis_ser.NewArticleLook().Clear()
-	redis_ser.NewCommentCount().Clear()
-
+// restoreCount 同步失败，把取出的计数加回去
+func restoreCount(countDB redis_ser.CountDB, countMap map[string]int) {
+	for id, num := range countMap {
+		if num == 0 {
+			continue
+		}
+		err := countDB.SetCount(id, num)
+		if err != nil {
+			global.Log.Errorf("%s %s 计数恢复失败 %d %s", countDB.Index, id, num, err)
+		}
+	}
 }
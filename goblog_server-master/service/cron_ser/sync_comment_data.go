@@ -4,26 +4,51 @@ import (
 	"gorm.io/gorm"
 	"gvb_server/global"
 	"gvb_server/models"
+	"gvb_server/service/comment_ser"
 	"gvb_server/service/redis_ser"
+	"strconv"
 )
 
 // SyncCommentData 同步redis评论数据到数据库
 func SyncCommentData() {
-	commentDiggInfo := redis_ser.NewCommentDigg().GetInfo()
-	for key, count := range commentDiggInfo {
-		var comment models.CommentModel
-		err := global.DB.Take(&comment, key).Error
+	commentDigg := redis_ser.NewCommentDigg()
+	for {
+		commentDiggInfo, err := commentDigg.TakeDirty(syncBatchSize)
 		if err != nil {
 			global.Log.Error(err)
-			continue
+			return
 		}
-		err = global.DB.Model(&comment).
-			Update("digg_count", gorm.Expr("digg + ?", count)).Error
-		if err != nil {
-			global.Log.Error(err)
-			continue
+		if len(commentDiggInfo) == 0 {
+			return
+		}
+		var failed bool
+		for key, count := range commentDiggInfo {
+			id, _ := strconv.Atoi(key)
+			err = global.DB.Model(&models.CommentModel{}).
+				Where("id = ?", id).
+				Update("digg_count", gorm.Expr("digg_count + ?", count)).Error
+			if err != nil {
+				global.Log.Error(err)
+				restoreCount(commentDigg, map[string]int{key: count})
+				failed = true
+			}
+		}
+		global.Log.Infof("同步评论点赞数 %d 条", len(commentDiggInfo))
+		// 有失败的等下次定时任务，避免同一批反复重试
+		if failed {
+			return
 		}
-		global.Log.Infof("%s 更新成功 点赞数为：%d", comment.Content, comment.CommentCount)
 	}
-	redis_ser.NewCommentDigg().Clear()
+}
+
+// ReconcileCommentCount 文章评论数写redis失败的文章，按数据库重新计算
+func ReconcileCommentCount() {
+	count, err := comment_ser.CommentService{}.ReconcileCount()
+	if err != nil {
+		global.Log.Error(err)
+		return
+	}
+	if count > 0 {
+		global.Log.Infof("重新计算文章评论数 %d 篇", count)
+	}
 }
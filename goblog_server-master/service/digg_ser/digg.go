@@ -0,0 +1,88 @@
+package digg_ser
+
+import (
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/service/redis_ser"
+	"gvb_server/utils"
+	"gvb_server/utils/jwts"
+)
+
+const (
+	DiggArticle = "article" // 文章点赞
+	DiggComment = "comment" // 评论点赞
+)
+
+// UserKey 登录用户按用户id，未登录按ip和ua的指纹
+func UserKey(userID uint, ip, userAgent string) string {
+	return utils.VisitorKey(userID, ip, userAgent)
+}
+
+// UserKeyByClaims claims为nil时按未登录处理
+func UserKeyByClaims(claims *jwts.CustomClaims, ip, userAgent string) (userKey string, userID uint) {
+	if claims != nil {
+		userID = claims.UserID
+	}
+	return UserKey(userID, ip, userAgent), userID
+}
+
+func countDB(diggType string) redis_ser.CountDB {
+	if diggType == DiggComment {
+		return redis_ser.NewCommentDigg()
+	}
+	return redis_ser.NewDigg()
+}
+
+// Toggle 点赞或取消点赞，返回操作后是否为点赞状态
+// 点赞记录的唯一索引保证同一个用户只算一次，计数只在状态真正变化时原子地加减
+func (DiggService) Toggle(diggType, targetID, userKey string, userID uint) (isDigg bool, err error) {
+	result := global.DB.Where("digg_type = ? and target_id = ? and user_key = ?", diggType, targetID, userKey).
+		Delete(&models.UserDiggModel{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		// 取消点赞
+		return false, countDB(diggType).SetCount(targetID, -1)
+	}
+
+	err = global.DB.Create(&models.UserDiggModel{
+		DiggType: diggType,
+		TargetID: targetID,
+		UserKey:  userKey,
+		UserID:   userID,
+	}).Error
+	if err != nil {
+		// 并发的重复点赞被唯一索引拦住，计数已经由另一个请求加过
+		if IsDigg(diggType, targetID, userKey) {
+			return true, nil
+		}
+		return false, err
+	}
+	return true, countDB(diggType).Set(targetID)
+}
+
+// IsDigg 用户是否点过赞
+func IsDigg(diggType, targetID, userKey string) bool {
+	var count int64
+	global.DB.Model(models.UserDiggModel{}).
+		Where("digg_type = ? and target_id = ? and user_key = ?", diggType, targetID, userKey).
+		Count(&count)
+	return count > 0
+}
+
+// DiggMap 一批文章或评论中用户点过赞的
+func DiggMap(diggType, userKey string, targetIDList []string) map[string]bool {
+	var diggMap = map[string]bool{}
+	if len(targetIDList) == 0 {
+		return diggMap
+	}
+	var idList []string
+	global.DB.Model(models.UserDiggModel{}).
+		Where("digg_type = ? and user_key = ? and target_id in ?", diggType, userKey, targetIDList).
+		Pluck("target_id", &idList)
+	for _, id := range idList {
+		diggMap[id] = true
+	}
+	return diggMap
+}
@@ -0,0 +1,4 @@
+package digg_ser
+
+type DiggService struct {
+}
@@ -1,13 +1,29 @@
 package service
 
 import (
+	"gvb_server/service/article_ser"
+	"gvb_server/service/comment_ser"
+	"gvb_server/service/digg_ser"
+	"gvb_server/service/feed_ser"
 	"gvb_server/service/image_ser"
+	"gvb_server/service/import_ser"
+	"gvb_server/service/notification_ser"
+	"gvb_server/service/seed_ser"
+	"gvb_server/service/static_ser"
 	"gvb_server/service/user_ser"
 )
 
 type ServiceGroup struct {
-	ImageService image_ser.ImageService
-	UserService  user_ser.UserService
+	ImageService        image_ser.ImageService
+	UserService         user_ser.UserService
+	ArticleService      article_ser.ArticleService
+	ImportService       import_ser.ImportService
+	StaticService       static_ser.StaticService
+	FeedService         feed_ser.FeedService
+	DiggService         digg_ser.DiggService
+	SeedService         seed_ser.SeedService
+	CommentService      comment_ser.CommentService
+	NotificationService notification_ser.NotificationService
 }
 
 var ServiceApp = new(ServiceGroup)
@@ -1,148 +1,63 @@
 package es_ser
 
 import (
-	"context"
-	"encoding/json"
-	"errors"
-	"github.com/olivere/elastic/v7"
-	"github.com/sirupsen/logrus"
-	"gvb_server/global"
 	"gvb_server/models"
+	"gvb_server/service/article_ser"
 	"gvb_server/service/redis_ser"
-	"strings"
 )
 
+// CommList 文章列表，加上redis中还没同步的点赞、浏览、评论数
 func CommList(option Option) (list []models.ArticleModel, count int, err error) {
-	if option.Key != "" {
-		option.Query.Must(
-			elastic.NewMultiMatchQuery(option.Key, option.Fields...),
-		)
-	}
-	// 根据标签搜
-	if option.Tag != "" {
-		option.Query.Must(
-			elastic.NewMultiMatchQuery(option.Tag, "tags"),
-		)
-	}
-	// 定义排序
-	type SortField struct {
-		Field     string
-		Ascending bool
-	}
-	sortField := SortField{
-		Field:     "created_at",
-		Ascending: false, // true从小到大 false从大到小
-	}
-	if option.Sort != "" {
-		_list := strings.Split(option.Sort, " ")
-		if len(_list) == 2 && (_list[1] == "desc" || _list[1] == "asc") {
-			sortField.Field = _list[0]
-			if _list[1] == "desc" {
-				sortField.Ascending = false
-			}
-			if _list[1] == "asc" {
-				sortField.Ascending = true
-			}
-		}
-	}
-
-	res, err := global.ESClient.
-		Search(models.ArticleModel{}.Index()).
-		Query(option.Query).
-		Highlight(elastic.NewHighlight().Field("title")).
-		From(option.GetForm()).
-		Sort(sortField.Field, sortField.Ascending).
-		Size(option.Limit).
-		Do(context.Background())
+	list, count, err = article_ser.NewRepository().List(article_ser.ListOption{
+		PageInfo:   option.PageInfo,
+		Fields:     option.Fields,
+		Tag:        option.Tag,
+		Category:   option.Category,
+		UserID:     option.UserID,
+		Status:     option.Status,
+		ShowHidden: option.ShowHidden,
+	})
 	if err != nil {
-		logrus.Error(err.Error())
 		return
 	}
 
-	count = int(res.Hits.TotalHits.Value) //搜索到的结果总条数
-	demoList := []models.ArticleModel{}
-
 	diggInfo := redis_ser.NewDigg().GetInfo()
 	lookInfo := redis_ser.NewArticleLook().GetInfo()
 	commentInfo := redis_ser.NewCommentCount().GetInfo()
-	for _, hit := range res.Hits.Hits {
-		var model models.ArticleModel
-		err = json.Unmarshal(hit.Source, &model)
-		if err != nil {
-			logrus.Error(err)
-			continue
-		}
-		title, ok := hit.Highlight["title"]
-		if ok {
-			model.Title = title[0]
-		}
-		model.ID = hit.Id
-		digg := diggInfo[hit.Id]
-		look := lookInfo[hit.Id]
-		comment := commentInfo[hit.Id]
-
-		model.DiggCount = model.DiggCount + digg
-		model.LookCount = model.LookCount + look
-		model.CommentCount = model.CommentCount + comment
-
-		demoList = append(demoList, model)
-	}
-	return demoList, count, err
-
+	var idList []string
+	for _, article := range list {
+		idList = append(idList, article.ID)
+	}
+	uvInfo := redis_ser.GetArticleUVMap(idList)
+	for i := range list {
+		id := list[i].ID
+		list[i].DiggCount = list[i].DiggCount + diggInfo[id]
+		list[i].LookCount = list[i].LookCount + lookInfo[id]
+		list[i].CommentCount = list[i].CommentCount + commentInfo[id]
+		list[i].UvCount = uvInfo[id]
+	}
+	return list, count, nil
 }
 
 // CommeDetail 根据id查
 func CommeDetail(id string) (model models.ArticleModel, err error) {
-	res, err := global.ESClient.
-		Get().
-		Index(models.ArticleModel{}.Index()).
-		Id(id).
-		Do(context.Background())
+	model, err = article_ser.NewRepository().Detail(id)
 	if err != nil {
-		logrus.Error(err.Error())
 		return
 	}
-	err = json.Unmarshal(res.Source, &model)
-	if err != nil {
-		return
-	}
-	model.ID = res.Id
-	model.LookCount = model.LookCount + redis_ser.NewArticleLook().Get(res.Id)
-	model.DiggCount = model.DiggCount + redis_ser.NewDigg().Get(res.Id)
-	model.CommentCount = model.CommentCount + redis_ser.NewCommentCount().Get(res.Id)
+	model.LookCount = model.LookCount + redis_ser.NewArticleLook().Get(id)
+	model.DiggCount = model.DiggCount + redis_ser.NewDigg().Get(id)
+	model.CommentCount = model.CommentCount + redis_ser.NewCommentCount().Get(id)
+	model.UvCount = redis_ser.GetArticleUV(id)
 	return
 }
 
 // CommeDetailByKeyword 根据keyword查
 func CommeDetailByKeyword(key string) (model models.ArticleModel, err error) {
-	res, err := global.ESClient.Search().
-		Index(models.ArticleModel{}.Index()).
-		Query(elastic.NewTermQuery("keyword", key)).
-		Size(1).
-		Do(context.Background())
-	if err != nil {
-		logrus.Error(err.Error())
-		return
-	}
-	if res.Hits.TotalHits.Value == 0 {
-		return model, errors.New("文章不存在")
-	}
-	hit := res.Hits.Hits[0]
-	err = json.Unmarshal(hit.Source, &model)
-	if err != nil {
-		return
-	}
-	model.ID = hit.Id
-	return
+	return article_ser.NewRepository().DetailByTitle(key)
 }
 
-// ArticleUpdate 更新点赞或收藏
+// ArticleUpdate 更新文章的部分字段
 func ArticleUpdate(id string, data map[string]any) error {
-	_, err := global.ESClient.
-		Update().
-		Index(models.ArticleModel{}.Index()).
-		Id(id).
-		Doc(data).Refresh("true").
-		Do(context.Background())
-	return err
+	return article_ser.NewRepository().Update(id, data)
 }
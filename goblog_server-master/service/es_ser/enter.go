@@ -1,15 +1,19 @@
 package es_ser
 
 import (
-	"github.com/olivere/elastic/v7"
 	"gvb_server/models"
+	"gvb_server/models/ctype"
 )
 
 type Option struct {
 	models.PageInfo
-	Fields []string
-	Tag    string
-	Query  *elastic.BoolQuery
+	Fields   []string
+	Tag      string
+	Category string
+	UserID   uint
+	Status   ctype.ArticleStatus
+	// ShowHidden 是否显示草稿、待发布、已归档的文章，仅管理员可用
+	ShowHidden bool
 }
 
 func (o *Option) GetForm() int {
@@ -20,6 +20,10 @@ type SearchData struct {
 
 // AsyncArticleByFullText 同步文章数据到全文搜索
 func AsyncArticleByFullText(id, title, content string) {
+	// 文章存在mysql时没有全文搜索索引
+	if global.ESClient == nil {
+		return
+	}
 	indexList := GetSearchIndexDataByContent(id, title, content)
 	// 批量添加
 	bulk := global.ESClient.Bulk()
@@ -37,6 +41,9 @@ func AsyncArticleByFullText(id, title, content string) {
 
 // DeleteFullTextByArticleID 删除文章搜索数据
 func DeleteFullTextByArticleID(id string) {
+	if global.ESClient == nil {
+		return
+	}
 	boolSearch := elastic.NewTermQuery("key", id)
 	result, _ := global.ESClient.
 		DeleteByQuery().
@@ -0,0 +1,4 @@
+package feed_ser
+
+type FeedService struct {
+}
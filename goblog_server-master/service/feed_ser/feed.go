@@ -0,0 +1,326 @@
+package feed_ser
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/service/article_ser"
+	"gvb_server/service/es_ser"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// 订阅中的文章数
+const feedSize = 20
+
+// Feed 订阅源，可以输出为rss、atom、json feed
+type Feed struct {
+	Title       string
+	Link        string // 网站地址
+	FeedLink    string // 订阅地址
+	Description string
+	Author      string
+	Email       string
+	Updated     time.Time
+	Items       []FeedItem
+}
+
+type FeedItem struct {
+	ID          string
+	Title       string
+	Link        string
+	Summary     string
+	ContentHTML string
+	Author      string
+	Image       string
+	Tags        []string
+	Published   time.Time
+	Updated     time.Time
+}
+
+// SiteURL 网站地址，不带结尾的 /
+func SiteURL() string {
+	return strings.TrimRight(global.Config.SiteInfo.Web, "/")
+}
+
+// ArticleURL 文章地址
+func ArticleURL(id string) string {
+	return fmt.Sprintf("%s/article/%s", SiteURL(), id)
+}
+
+// BuildFeed 最新的已发布文章，可以按标签或分类过滤
+func (FeedService) BuildFeed(tag, category, feedPath string) (feed Feed, err error) {
+	list, _, err := es_ser.CommList(es_ser.Option{
+		PageInfo: models.PageInfo{Limit: feedSize, Sort: "created_at desc"},
+		Tag:      tag,
+		Category: category,
+	})
+	if err != nil {
+		return feed, err
+	}
+
+	site := global.Config.SiteInfo
+	feed = Feed{
+		Title:       site.Title,
+		Link:        SiteURL() + "/",
+		FeedLink:    SiteURL() + feedPath,
+		Description: site.Slogan,
+		Author:      site.Name,
+		Email:       site.Email,
+	}
+	if tag != "" {
+		feed.Title = fmt.Sprintf("%s - 标签：%s", site.Title, tag)
+		feed.Link = fmt.Sprintf("%s/tag/%s/", SiteURL(), url.PathEscape(tag))
+	}
+	if category != "" {
+		feed.Title = fmt.Sprintf("%s - 分类：%s", site.Title, category)
+		feed.Link = fmt.Sprintf("%s/category/%s/", SiteURL(), url.PathEscape(category))
+	}
+
+	articleService := article_ser.ArticleService{}
+	for _, article := range list {
+		item := FeedItem{
+			ID:          article.ID,
+			Title:       article.Title,
+			Link:        ArticleURL(article.ID),
+			Summary:     article.Abstract,
+			ContentHTML: articleService.RenderHTML(article.Content),
+			Author:      article.UserNickName,
+			Image:       article.BannerUrl,
+			Tags:        article.Tags,
+			Published:   parseTime(article.CreatedAt),
+			Updated:     parseTime(article.UpdatedAt),
+		}
+		if item.Updated.Before(item.Published) {
+			item.Updated = item.Published
+		}
+		if item.Updated.After(feed.Updated) {
+			feed.Updated = item.Updated
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	if feed.Updated.IsZero() {
+		feed.Updated = parseTime(site.CreatedAt)
+	}
+	return feed, nil
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.ParseInLocation("2006-01-02 15:04:05", s, time.Local)
+	return t
+}
+
+type rss struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Content string     `xml:"xmlns:content,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description"`
+	Content     cdata    `xml:"content:encoded"`
+	Author      string   `xml:"author,omitempty"`
+	Category    []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// RSS rss 2.0
+func (f Feed) RSS() ([]byte, error) {
+	channel := rssChannel{
+		Title:         f.Title,
+		Link:          f.Link,
+		AtomLink:      atomLink{Href: f.FeedLink, Rel: "self", Type: "application/rss+xml"},
+		Description:   f.Description,
+		LastBuildDate: f.Updated.Format(time.RFC1123Z),
+	}
+	for _, item := range f.Items {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        item.Link,
+			Description: item.Summary,
+			Content:     cdata{item.ContentHTML},
+			Author:      rssAuthor(f.Email, item.Author),
+			Category:    item.Tags,
+			PubDate:     item.Published.Format(time.RFC1123Z),
+		})
+	}
+	return marshalXML(rss{
+		Version: "2.0",
+		Atom:    "http://www.w3.org/2005/Atom",
+		Content: "http://purl.org/rss/1.0/modules/content/",
+		Channel: channel,
+	})
+}
+
+// rssAuthor rss的author要求是邮箱
+func rssAuthor(email, name string) string {
+	if email == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", email, name)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Link    []atomLink  `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+}
+
+type atomEntry struct {
+	ID        string         `xml:"id"`
+	Title     string         `xml:"title"`
+	Link      atomLink       `xml:"link"`
+	Published string         `xml:"published"`
+	Updated   string         `xml:"updated"`
+	Author    atomAuthor     `xml:"author"`
+	Summary   atomText       `xml:"summary"`
+	Content   atomText       `xml:"content"`
+	Category  []atomCategory `xml:"category"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// Atom atom 1.0
+func (f Feed) Atom() ([]byte, error) {
+	feed := atomFeed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		ID:      f.FeedLink,
+		Title:   f.Title,
+		Updated: f.Updated.Format(time.RFC3339),
+		Link: []atomLink{
+			{Href: f.Link},
+			{Href: f.FeedLink, Rel: "self", Type: "application/atom+xml"},
+		},
+		Author: atomAuthor{Name: f.Author, Email: f.Email},
+	}
+	for _, item := range f.Items {
+		entry := atomEntry{
+			ID:        item.Link,
+			Title:     item.Title,
+			Link:      atomLink{Href: item.Link},
+			Published: item.Published.Format(time.RFC3339),
+			Updated:   item.Updated.Format(time.RFC3339),
+			Author:    atomAuthor{Name: item.Author},
+			Summary:   atomText{Type: "text", Value: item.Summary},
+			Content:   atomText{Type: "html", Value: item.ContentHTML},
+		}
+		for _, tag := range item.Tags {
+			entry.Category = append(entry.Category, atomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return marshalXML(feed)
+}
+
+func marshalXML(v any) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	FeedURL     string         `json:"feed_url"`
+	Description string         `json:"description,omitempty"`
+	Authors     []jsonAuthor   `json:"authors,omitempty"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string       `json:"id"`
+	URL           string       `json:"url"`
+	Title         string       `json:"title"`
+	ContentHTML   string       `json:"content_html"`
+	Summary       string       `json:"summary,omitempty"`
+	Image         string       `json:"image,omitempty"`
+	DatePublished string       `json:"date_published"`
+	DateModified  string       `json:"date_modified"`
+	Authors       []jsonAuthor `json:"authors,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+}
+
+// JSON json feed 1.1
+func (f Feed) JSON() ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedLink,
+		Description: f.Description,
+		Items:       []jsonFeedItem{},
+	}
+	if f.Author != "" {
+		feed.Authors = []jsonAuthor{{Name: f.Author}}
+	}
+	for _, item := range f.Items {
+		jsonItem := jsonFeedItem{
+			ID:            item.Link,
+			URL:           item.Link,
+			Title:         item.Title,
+			ContentHTML:   item.ContentHTML,
+			Summary:       item.Summary,
+			Image:         item.Image,
+			DatePublished: item.Published.Format(time.RFC3339),
+			DateModified:  item.Updated.Format(time.RFC3339),
+			Tags:          item.Tags,
+		}
+		if item.Author != "" {
+			jsonItem.Authors = []jsonAuthor{{Name: item.Author}}
+		}
+		feed.Items = append(feed.Items, jsonItem)
+	}
+	return json.MarshalIndent(feed, "", "  ")
+}
@@ -0,0 +1,141 @@
+package feed_ser
+
+import (
+	"encoding/xml"
+	"fmt"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/service/article_ser"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 单个sitemap文件最多的url数，超过后拆分并生成sitemap索引
+const sitemapSize = 50000
+
+// SitemapIndexKey sitemap.xml 对应的key，拆分后的文件key是页码
+const SitemapIndexKey = "index"
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+const sitemapXmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// SitemapPageURL 拆分后第page个sitemap的地址
+func SitemapPageURL(page int) string {
+	return fmt.Sprintf("%s/sitemaps/%d.xml", SiteURL(), page)
+}
+
+// BuildSitemap 生成首页、菜单、标签页和所有已发布文章的sitemap
+// 返回 key -> xml，不超过50000条时只有index，超过后index为sitemap索引，1、2...为拆分的文件
+func (FeedService) BuildSitemap() (map[string][]byte, error) {
+	urlList := []sitemapURL{{Loc: SiteURL() + "/"}}
+
+	// 菜单，路由别名不是路径，跳过
+	var menuList []models.MenuModel
+	global.DB.Order("sort").Find(&menuList)
+	for _, menu := range menuList {
+		if !strings.HasPrefix(menu.Path, "/") || menu.Path == "/" {
+			continue
+		}
+		urlList = append(urlList, sitemapURL{Loc: SiteURL() + menu.Path})
+	}
+
+	// 文章，按更新时间倒序
+	repository := article_ser.NewRepository()
+	option := article_ser.ListOption{PageInfo: models.PageInfo{Limit: 1000, Sort: "updated_at desc"}}
+	tagMap := map[string]string{}
+	var tagList []string
+	var articleURLList []sitemapURL
+	var total int
+	for option.Page = 1; ; option.Page++ {
+		list, count, err := repository.List(option)
+		if err != nil {
+			return nil, err
+		}
+		for _, article := range list {
+			articleURLList = append(articleURLList, sitemapURL{
+				Loc:     ArticleURL(article.ID),
+				LastMod: lastMod(article.UpdatedAt),
+			})
+			for _, tag := range article.Tags {
+				if _, ok := tagMap[tag]; !ok {
+					tagList = append(tagList, tag)
+					// 文章按更新时间倒序，第一次出现就是标签页最后的更新时间
+					tagMap[tag] = lastMod(article.UpdatedAt)
+				}
+			}
+		}
+		total += len(list)
+		if len(list) == 0 || total >= count {
+			break
+		}
+	}
+	for _, tag := range tagList {
+		urlList = append(urlList, sitemapURL{
+			Loc:     fmt.Sprintf("%s/tag/%s/", SiteURL(), url.PathEscape(tag)),
+			LastMod: tagMap[tag],
+		})
+	}
+	urlList = append(urlList, articleURLList...)
+
+	pageMap := map[string][]byte{}
+	if len(urlList) <= sitemapSize {
+		data, err := marshalXML(urlSet{Xmlns: sitemapXmlns, URLs: urlList})
+		if err != nil {
+			return nil, err
+		}
+		pageMap[SitemapIndexKey] = data
+		return pageMap, nil
+	}
+
+	index := sitemapIndex{Xmlns: sitemapXmlns}
+	for page := 1; (page-1)*sitemapSize < len(urlList); page++ {
+		start := (page - 1) * sitemapSize
+		end := start + sitemapSize
+		if end > len(urlList) {
+			end = len(urlList)
+		}
+		data, err := marshalXML(urlSet{Xmlns: sitemapXmlns, URLs: urlList[start:end]})
+		if err != nil {
+			return nil, err
+		}
+		pageMap[strconv.Itoa(page)] = data
+		index.Sitemaps = append(index.Sitemaps, sitemapURL{Loc: SitemapPageURL(page)})
+	}
+	data, err := marshalXML(index)
+	if err != nil {
+		return nil, err
+	}
+	pageMap[SitemapIndexKey] = data
+	return pageMap, nil
+}
+
+// lastMod sitemap要求的W3C时间格式
+func lastMod(updatedAt string) string {
+	t := parseTime(updatedAt)
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02T15:04:05-07:00")
+}
+
+// Robots robots.txt，不让爬api
+func (FeedService) Robots() string {
+	return fmt.Sprintf("User-agent: *\nAllow: /\nDisallow: /api/\n\nSitemap: %s/sitemap.xml\n", SiteURL())
+}
@@ -0,0 +1,4 @@
+package import_ser
+
+type ImportService struct {
+}
@@ -0,0 +1,189 @@
+package import_ser
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/service/article_ser"
+	"gvb_server/service/es_ser"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Author 导入文章的作者
+type Author struct {
+	UserID   uint
+	NickName string
+	Avatar   string
+}
+
+// ImportResult 每个文件的导入结果
+type ImportResult struct {
+	File      string `json:"file"`
+	Title     string `json:"title"`
+	ArticleID string `json:"article_id"`
+	IsSuccess bool   `json:"is_success"`
+	Msg       string `json:"msg"`
+}
+
+// IsMarkdown 是否是markdown文件
+func IsMarkdown(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// ImportFile 导入一个markdown文件
+func (s ImportService) ImportFile(name string, data []byte, author Author) ImportResult {
+	result := ImportResult{File: name}
+	post, err := ParseMarkdown(name, data)
+	if err != nil {
+		result.Msg = err.Error()
+		return result
+	}
+	result.Title = post.Title
+
+	articleService := article_ser.ArticleService{}
+	content, text, filtered := articleService.FilterXSS(post.Content)
+	if filtered {
+		global.Log.Warnf("XSS攻击已被过滤！导入文件：%s", name)
+	}
+	if post.Abstract == "" {
+		post.Abstract = articleService.GetAbstract(text)
+	} else {
+		_, abstractText, _ := articleService.FilterXSS(post.Abstract)
+		post.Abstract = articleService.GetAbstract(abstractText)
+	}
+
+	status := ctype.ArticlePublished
+	if post.Draft {
+		status = ctype.ArticleDraft
+	}
+	article := models.ArticleModel{
+		CreatedAt:    post.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:    post.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Title:        post.Title,
+		Keyword:      post.Title,
+		Abstract:     post.Abstract,
+		Content:      content,
+		UserID:       author.UserID,
+		UserNickName: author.NickName,
+		UserAvatar:   author.Avatar,
+		Category:     post.Category,
+		Tags:         post.Tags,
+		Status:       status,
+		PublishAt:    post.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	// 有封面用封面地址，没有就随机选一张banner
+	if post.Cover != "" {
+		article.BannerUrl = post.Cover
+	} else {
+		article.BannerID, article.BannerUrl = randomBanner()
+	}
+
+	repository := article_ser.NewRepository()
+	if repository.IsExistTitle(article.Title) {
+		result.Msg = "文章已存在"
+		return result
+	}
+	err = repository.Create(&article)
+	if err != nil {
+		global.Log.Error(err)
+		result.Msg = err.Error()
+		return result
+	}
+	_, err = articleService.CreateRevision(article, author.UserID, author.NickName, "导入文章")
+	if err != nil {
+		global.Log.Error(err)
+	}
+	if article.Status.IsPublished() {
+		es_ser.AsyncArticleByFullText(article.ID, article.Title, article.Content)
+	}
+
+	result.ArticleID = article.ID
+	result.IsSuccess = true
+	result.Msg = "导入成功"
+	return result
+}
+
+// ImportDir 导入目录下所有的markdown文件，包括子目录
+func (s ImportService) ImportDir(dir string, author Author) (resultList []ImportResult, err error) {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !IsMarkdown(path) {
+			return nil
+		}
+		name, _ := filepath.Rel(dir, path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			resultList = append(resultList, ImportResult{File: name, Msg: err.Error()})
+			return nil
+		}
+		resultList = append(resultList, s.ImportFile(name, data, author))
+		return nil
+	})
+	return resultList, err
+}
+
+// ImportZip 导入压缩包里所有的markdown文件
+func (s ImportService) ImportZip(reader io.ReaderAt, size int64, author Author) (resultList []ImportResult, err error) {
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, errors.New("压缩包格式错误")
+	}
+	for _, file := range zipReader.File {
+		// mac打包时带的元数据
+		if file.FileInfo().IsDir() || strings.HasPrefix(file.Name, "__MACOSX/") || !IsMarkdown(file.Name) {
+			continue
+		}
+		data, err := readZipFile(file)
+		if err != nil {
+			resultList = append(resultList, ImportResult{File: file.Name, Msg: err.Error()})
+			continue
+		}
+		resultList = append(resultList, s.ImportFile(file.Name, data, author))
+	}
+	return resultList, nil
+}
+
+// readZipFile 读取压缩包里的文件，超过设定大小的不导入，不相信压缩包里记录的大小，解压时限制读取的字节数
+func readZipFile(file *zip.File) ([]byte, error) {
+	maxSize := global.Config.Upload.GetImportSize()
+	if file.UncompressedSize64 > uint64(maxSize) {
+		return nil, fmt.Errorf("文件大小超过设定大小 %dMB", maxSize>>20)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("文件大小超过设定大小 %dMB", maxSize>>20)
+	}
+	return data, nil
+}
+
+// randomBanner 随机选一张banner
+func randomBanner() (id uint, path string) {
+	var bannerList []models.BannerModel
+	global.DB.Select("id", "path").Find(&bannerList)
+	if len(bannerList) == 0 {
+		return 0, ""
+	}
+	rand.Seed(time.Now().UnixNano())
+	banner := bannerList[rand.Intn(len(bannerList))]
+	return banner.ID, banner.Path
+}
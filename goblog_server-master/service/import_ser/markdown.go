@@ -0,0 +1,156 @@
+package import_ser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"gvb_server/models/ctype"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// frontMatter Hexo、Hugo文章头部的yaml
+type frontMatter struct {
+	Title       string `yaml:"title"`
+	Date        any    `yaml:"date"`
+	Updated     any    `yaml:"updated"` // hexo
+	LastMod     any    `yaml:"lastmod"` // hugo
+	Tags        any    `yaml:"tags"`
+	Categories  any    `yaml:"categories"`
+	Cover       string `yaml:"cover"`
+	Description string `yaml:"description"`
+	Draft       bool   `yaml:"draft"`
+}
+
+// Post 从markdown文件解析出来的文章
+type Post struct {
+	Title     string
+	Abstract  string
+	Content   string
+	Category  string
+	Tags      ctype.Array
+	Cover     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Draft     bool
+}
+
+// hexo用来分隔摘要和正文的标记
+const moreTag = "<!-- more -->"
+
+var timeLayoutList = []string{
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+}
+
+// ParseMarkdown 解析带yaml头的markdown，没有标题时用文件名
+func ParseMarkdown(name string, data []byte) (post Post, err error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	var matter frontMatter
+	if strings.HasPrefix(text, "---\n") {
+		rest := "\n" + text[4:]
+		end := strings.Index(rest, "\n---")
+		if end == -1 {
+			return post, errors.New("文章头部格式错误，缺少结束的 ---")
+		}
+		err = yaml.Unmarshal([]byte(rest[:end]), &matter)
+		if err != nil {
+			return post, fmt.Errorf("文章头部解析失败 %s", err)
+		}
+		// 去掉结束的---所在的行
+		text = rest[end+4:]
+		if i := strings.Index(text, "\n"); i != -1 {
+			text = text[i+1:]
+		} else {
+			text = ""
+		}
+	}
+
+	post.Title = strings.TrimSpace(matter.Title)
+	if post.Title == "" {
+		post.Title = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	}
+	post.Content = strings.TrimSpace(text)
+	if post.Content == "" {
+		return post, errors.New("文章内容为空")
+	}
+	post.Abstract = strings.TrimSpace(matter.Description)
+	if post.Abstract == "" {
+		if i := strings.Index(post.Content, moreTag); i != -1 {
+			post.Abstract = strings.TrimSpace(post.Content[:i])
+		}
+	}
+	post.Content = strings.Replace(post.Content, moreTag, "", 1)
+
+	post.Tags = toList(matter.Tags)
+	// 分类只保留一个，hexo的多级分类取第一级
+	if categoryList := toList(matter.Categories); len(categoryList) > 0 {
+		post.Category = categoryList[0]
+	}
+	post.Cover = strings.TrimSpace(matter.Cover)
+	post.Draft = matter.Draft
+
+	post.CreatedAt, err = parseTime(matter.Date)
+	if err != nil {
+		return post, fmt.Errorf("date格式错误 %v", matter.Date)
+	}
+	post.UpdatedAt, _ = parseTime(matter.LastMod)
+	if post.UpdatedAt.IsZero() {
+		post.UpdatedAt, _ = parseTime(matter.Updated)
+	}
+	if post.CreatedAt.IsZero() {
+		post.CreatedAt = time.Now()
+	}
+	if post.UpdatedAt.IsZero() {
+		post.UpdatedAt = post.CreatedAt
+	}
+	return post, nil
+}
+
+// parseTime 没有填时间返回零值
+func parseTime(value any) (time.Time, error) {
+	switch val := value.(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return val.Local(), nil
+	}
+	s := strings.TrimSpace(fmt.Sprint(value))
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timeLayoutList {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err == nil {
+			return t.Local(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("时间格式错误 %s", s)
+}
+
+// toList tags、categories可以是字符串，也可以是列表或者嵌套列表
+func toList(value any) (list ctype.Array) {
+	switch val := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		for _, v := range val {
+			list = append(list, toList(v)...)
+		}
+		return list
+	}
+	s := strings.TrimSpace(fmt.Sprint(value))
+	if s == "" {
+		return nil
+	}
+	return ctype.Array{s}
+}
@@ -0,0 +1,163 @@
+package import_ser
+
+import (
+	"archive/zip"
+	"bytes"
+	"gvb_server/config"
+	"gvb_server/global"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local)
+	cases := []struct {
+		name     string
+		file     string
+		data     string
+		title    string
+		tags     []string
+		category string
+		created  time.Time
+		abstract string
+		wantErr  bool
+	}{
+		{
+			name:  "没有头部用文件名做标题",
+			file:  "posts/hello.md",
+			data:  "正文",
+			title: "hello",
+		},
+		{
+			name:    "头部缺少结束的---",
+			file:    "a.md",
+			data:    "---\ntitle: a\n正文",
+			wantErr: true,
+		},
+		{
+			name:    "头部不是合法的yaml",
+			file:    "a.md",
+			data:    "---\ntitle: [a\n---\n正文",
+			wantErr: true,
+		},
+		{
+			name:    "只有头部没有正文",
+			file:    "a.md",
+			data:    "---\ntitle: a\n---\n",
+			wantErr: true,
+		},
+		{
+			name:    "日期格式错误",
+			file:    "a.md",
+			data:    "---\ntitle: a\ndate: 昨天\n---\n正文",
+			wantErr: true,
+		},
+		{
+			name:    "日期",
+			file:    "a.md",
+			data:    "---\ntitle: a\ndate: 2024-03-01\n---\n正文",
+			title:   "a",
+			created: day,
+		},
+		{
+			name:    "日期时间",
+			file:    "a.md",
+			data:    "---\ntitle: a\ndate: 2024-03-01 08:30:00\n---\n正文",
+			title:   "a",
+			created: day.Add(8*time.Hour + 30*time.Minute),
+		},
+		{
+			name:    "斜杠分隔的日期",
+			file:    "a.md",
+			data:    "---\ntitle: a\ndate: 2024/03/01\n---\n正文",
+			title:   "a",
+			created: day,
+		},
+		{
+			name:    "带时区的日期时间",
+			file:    "a.md",
+			data:    "---\ntitle: a\ndate: 2024-03-01T00:00:00Z\n---\n正文",
+			title:   "a",
+			created: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "标签和分类是字符串",
+			file:     "a.md",
+			data:     "---\ntitle: a\ntags: go\ncategories: 后端\n---\n正文",
+			title:    "a",
+			tags:     []string{"go"},
+			category: "后端",
+		},
+		{
+			name:     "标签是列表，分类是嵌套列表",
+			file:     "a.md",
+			data:     "---\ntitle: a\ntags: [go, gin]\ncategories:\n  - [后端, web]\n---\n正文",
+			title:    "a",
+			tags:     []string{"go", "gin"},
+			category: "后端",
+		},
+		{
+			name:     "hexo的more标记分隔摘要",
+			file:     "a.md",
+			data:     "\xef\xbb\xbf---\r\ntitle: a\r\n---\r\n摘要\r\n<!-- more -->\r\n正文",
+			title:    "a",
+			abstract: "摘要",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			post, err := ParseMarkdown(c.file, []byte(c.data))
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %+v", post)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if post.Title != c.title || post.Category != c.category || post.Abstract != c.abstract {
+				t.Fatalf("got %+v", post)
+			}
+			if strings.Join(post.Tags, ",") != strings.Join(c.tags, ",") {
+				t.Fatalf("tags: got %v, want %v", post.Tags, c.tags)
+			}
+			if !c.created.IsZero() && !post.CreatedAt.Equal(c.created) {
+				t.Fatalf("created: got %s, want %s", post.CreatedAt, c.created)
+			}
+			if strings.Contains(post.Content, moreTag) {
+				t.Fatalf("content: %q", post.Content)
+			}
+		})
+	}
+}
+
+func TestReadZipFileLimit(t *testing.T) {
+	global.Config = &config.Config{Upload: config.Upload{ImportSize: 1}}
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for name, size := range map[string]int{"small.md": 1 << 10, "large.md": 1<<20 + 1} {
+		w, _ := writer.Create(name)
+		w.Write(bytes.Repeat([]byte("a"), size))
+	}
+	writer.Close()
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range reader.File {
+		data, err := readZipFile(file)
+		switch file.Name {
+		case "small.md":
+			if err != nil || len(data) != 1<<10 {
+				t.Fatalf("small: %d %v", len(data), err)
+			}
+		case "large.md":
+			if err == nil {
+				t.Fatalf("large: %d", len(data))
+			}
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package notification_ser
+
+import (
+	"fmt"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/plugins/email"
+	"html"
+	"strings"
+)
+
+// digestLimit 一封邮件里最多列出的通知数
+const digestLimit = 20
+
+// SendDigest 把还没发过邮件的未读通知汇总，发到用户绑定的邮箱，发过的不会重复发
+func (NotificationService) SendDigest() {
+	if !global.Config.Notification.EmailDigest {
+		return
+	}
+	var userList []models.UserModel
+	global.DB.Where("email <> '' and id in (?)", global.DB.Model(&models.NotificationModel{}).
+		Where("is_read = ? and is_emailed = ?", false, false).
+		Select("rev_user_id")).
+		Find(&userList)
+	for _, user := range userList {
+		var list []models.NotificationModel
+		global.DB.Where("rev_user_id = ? and is_read = ? and is_emailed = ?", user.ID, false, false).
+			Order("id desc").Find(&list)
+		if len(list) == 0 {
+			continue
+		}
+		err := email.NewDigest().Send(user.Email, DigestBody(user, list))
+		if err != nil {
+			global.Log.Errorf("发送通知摘要给 %s 失败 %s", user.Email, err)
+			continue
+		}
+		var idList []uint
+		for _, notification := range list {
+			idList = append(idList, notification.ID)
+		}
+		global.DB.Model(&models.NotificationModel{}).Where("id in ?", idList).Update("is_emailed", true)
+	}
+}
+
+// DigestBody 邮件摘要的正文
+func DigestBody(user models.UserModel, list []models.NotificationModel) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("<p>%s，你有 %d 条未读通知：</p><ul>", html.EscapeString(user.NickName), len(list)))
+	for i, notification := range list {
+		if i >= digestLimit {
+			builder.WriteString(fmt.Sprintf("<li>还有 %d 条...</li>", len(list)-digestLimit))
+			break
+		}
+		builder.WriteString(fmt.Sprintf("<li>[%s] %s：%s</li>", notification.Type,
+			html.EscapeString(notification.Title), html.EscapeString(notification.Content)))
+	}
+	builder.WriteString("</ul>")
+	return builder.String()
+}
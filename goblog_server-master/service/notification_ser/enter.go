@@ -0,0 +1,4 @@
+package notification_ser
+
+type NotificationService struct {
+}
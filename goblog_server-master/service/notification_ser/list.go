@@ -0,0 +1,63 @@
+package notification_ser
+
+import (
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+)
+
+// List 用户的通知，新的在前，notifyType为0时不按类型过滤
+func (NotificationService) List(userID uint, notifyType ctype.NotificationType, unread bool, page models.PageInfo) (list []models.NotificationModel, count int64, err error) {
+	query := global.DB.Model(&models.NotificationModel{}).Where("rev_user_id = ?", userID)
+	if notifyType != 0 {
+		query = query.Where("type = ?", notifyType)
+	}
+	if unread {
+		query = query.Where("is_read = ?", false)
+	}
+	err = query.Count(&count).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if page.Limit <= 0 {
+		page.Limit = 10
+	}
+	offset := (page.Page - 1) * page.Limit
+	if offset < 0 {
+		offset = 0
+	}
+	err = query.Order("id desc").Limit(page.Limit).Offset(offset).Find(&list).Error
+	return list, count, err
+}
+
+// UnreadCount 未读通知数，按类型分组
+func (NotificationService) UnreadCount(userID uint) (count int64, typeCount map[ctype.NotificationType]int64, err error) {
+	var rowList []struct {
+		Type  ctype.NotificationType
+		Count int64
+	}
+	err = global.DB.Model(&models.NotificationModel{}).
+		Select("type, count(*) as count").
+		Where("rev_user_id = ? and is_read = ?", userID, false).
+		Group("type").
+		Scan(&rowList).Error
+	if err != nil {
+		return 0, nil, err
+	}
+	typeCount = map[ctype.NotificationType]int64{}
+	for _, row := range rowList {
+		typeCount[row.Type] = row.Count
+		count += row.Count
+	}
+	return count, typeCount, nil
+}
+
+// MarkRead 标记已读，idList为空时全部标记，返回标记的数量
+func (NotificationService) MarkRead(userID uint, idList []uint) (int64, error) {
+	query := global.DB.Model(&models.NotificationModel{}).Where("rev_user_id = ? and is_read = ?", userID, false)
+	if len(idList) > 0 {
+		query = query.Where("id in ?", idList)
+	}
+	result := query.Update("is_read", true)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,218 @@
+package notification_ser
+
+import (
+	"fmt"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/service/push_ser"
+	"gvb_server/utils"
+)
+
+// MentionLimit 一条内容最多通知几个被@的人
+const MentionLimit = 10
+
+// excerpt 通知里的内容摘要
+func excerpt(content string) string {
+	runeList := []rune(content)
+	if len(runeList) <= 100 {
+		return content
+	}
+	return string(runeList[:100]) + "..."
+}
+
+// newNotification 发给revUserID的通知，带上触发人的信息
+func newNotification(notifyType ctype.NotificationType, revUserID uint, sender models.UserModel, title, content string) models.NotificationModel {
+	return models.NotificationModel{
+		RevUserID:        revUserID,
+		Type:             notifyType,
+		SendUserID:       sender.ID,
+		SendUserNickName: sender.NickName,
+		SendUserAvatar:   sender.Avatar,
+		Title:            title,
+		Content:          excerpt(content),
+	}
+}
+
+// Send 保存通知，不会通知自己
+func (NotificationService) Send(list ...models.NotificationModel) error {
+	var sendList []models.NotificationModel
+	for _, notification := range list {
+		if notification.RevUserID == 0 || notification.RevUserID == notification.SendUserID {
+			continue
+		}
+		sendList = append(sendList, notification)
+	}
+	if len(sendList) == 0 {
+		return nil
+	}
+	err := global.DB.CreateInBatches(&sendList, 500).Error
+	if err != nil {
+		return err
+	}
+	push(sendList)
+	return nil
+}
+
+// push 实时推送给在线的用户，公告只推一次给所有连接，客户端收到后自己刷新未读数
+func push(list []models.NotificationModel) {
+	var isBroadcast bool
+	for _, notification := range list {
+		if notification.Type == ctype.NotifyBroadcast {
+			isBroadcast = true
+			continue
+		}
+		push_ser.Push(notification.RevUserID, "notification", notification)
+	}
+	if !isBroadcast {
+		return
+	}
+	err := push_ser.Publish(0, "broadcast", map[string]string{
+		"title":   list[0].Title,
+		"content": list[0].Content,
+	})
+	if err != nil {
+		global.Log.Error(err)
+	}
+}
+
+// mentionUserList 内容里@到的用户，用户名是唯一的，先按用户名匹配，没有再按昵称匹配
+// 昵称不唯一，多个用户同名时不知道@的是谁，跳过
+func mentionUserList(content string) (userList []models.UserModel) {
+	nameList := utils.ParseMentions(content, MentionLimit)
+	if len(nameList) == 0 {
+		return nil
+	}
+	var candidateList []models.UserModel
+	global.DB.Find(&candidateList, "user_name in ? or nick_name in ?", nameList, nameList)
+	var userNameMap = map[string]models.UserModel{}
+	var nickNameMap = map[string][]models.UserModel{}
+	for _, user := range candidateList {
+		userNameMap[user.UserName] = user
+		nickNameMap[user.NickName] = append(nickNameMap[user.NickName], user)
+	}
+	var seen = map[uint]bool{}
+	for _, name := range nameList {
+		user, ok := userNameMap[name]
+		if !ok {
+			if len(nickNameMap[name]) != 1 {
+				continue
+			}
+			user = nickNameMap[name][0]
+		}
+		if seen[user.ID] {
+			continue
+		}
+		seen[user.ID] = true
+		userList = append(userList, user)
+	}
+	return userList
+}
+
+// Comment 评论通过审核后，通知被回复的人和被@的人，同一个人只通知一次
+func (s NotificationService) Comment(comment models.CommentModel) error {
+	var sender models.UserModel
+	err := global.DB.Take(&sender, comment.UserID).Error
+	if err != nil {
+		return err
+	}
+	var list []models.NotificationModel
+	var notified = map[uint]bool{sender.ID: true}
+	if comment.ParentCommentID != nil {
+		var parent models.CommentModel
+		err = global.DB.Take(&parent, *comment.ParentCommentID).Error
+		if err == nil && !notified[parent.UserID] {
+			notified[parent.UserID] = true
+			notification := newNotification(ctype.NotifyReply, parent.UserID, sender,
+				fmt.Sprintf("%s 回复了你的评论", sender.NickName), comment.Content)
+			notification.ArticleID = comment.ArticleID
+			notification.CommentID = comment.ID
+			list = append(list, notification)
+		}
+	}
+	for _, user := range mentionUserList(comment.Content) {
+		if notified[user.ID] {
+			continue
+		}
+		notified[user.ID] = true
+		notification := newNotification(ctype.NotifyMention, user.ID, sender,
+			fmt.Sprintf("%s 在评论中提到了你", sender.NickName), comment.Content)
+		notification.ArticleID = comment.ArticleID
+		notification.CommentID = comment.ID
+		list = append(list, notification)
+	}
+	return s.Send(list...)
+}
+
+// interact 点赞和收藏的通知，同一个人对同一个目标的未读通知只保留一条，反复点赞不会刷屏
+func (s NotificationService) interact(notifyType ctype.NotificationType, senderID, revUserID uint, articleID string, commentID uint, title, content string) error {
+	if senderID == 0 || senderID == revUserID {
+		return nil
+	}
+	var count int64
+	global.DB.Model(&models.NotificationModel{}).
+		Where("rev_user_id = ? and is_read = ? and type = ? and send_user_id = ? and article_id = ? and comment_id = ?",
+			revUserID, false, notifyType, senderID, articleID, commentID).
+		Count(&count)
+	if count > 0 {
+		return nil
+	}
+	var sender models.UserModel
+	err := global.DB.Take(&sender, senderID).Error
+	if err != nil {
+		return err
+	}
+	notification := newNotification(notifyType, revUserID, sender, fmt.Sprintf(title, sender.NickName), content)
+	notification.ArticleID = articleID
+	notification.CommentID = commentID
+	return s.Send(notification)
+}
+
+// LikeArticle 文章被点赞，通知作者
+func (s NotificationService) LikeArticle(senderID uint, article models.ArticleModel) error {
+	return s.interact(ctype.NotifyLike, senderID, article.UserID, article.ID, 0, "%s 赞了你的文章", article.Title)
+}
+
+// LikeComment 评论被点赞，通知评论人
+func (s NotificationService) LikeComment(senderID uint, comment models.CommentModel) error {
+	return s.interact(ctype.NotifyLike, senderID, comment.UserID, comment.ArticleID, comment.ID, "%s 赞了你的评论", comment.Content)
+}
+
+// Collect 文章被收藏，通知作者
+func (s NotificationService) Collect(senderID uint, article models.ArticleModel) error {
+	return s.interact(ctype.NotifyCollect, senderID, article.UserID, article.ID, 0, "%s 收藏了你的文章", article.Title)
+}
+
+// System 发给某个用户的系统通知
+func (s NotificationService) System(revUserID uint, title, content string) error {
+	return s.Send(models.NotificationModel{
+		RevUserID: revUserID,
+		Type:      ctype.NotifySystem,
+		Title:     title,
+		Content:   excerpt(content),
+	})
+}
+
+// Broadcast 管理员发通知，userIDList为空时发给所有用户（公告），否则发给指定的用户（系统通知），返回通知的人数
+func (s NotificationService) Broadcast(title, content string, userIDList []uint) (count int, err error) {
+	notifyType := ctype.NotifySystem
+	if len(userIDList) == 0 {
+		notifyType = ctype.NotifyBroadcast
+		err = global.DB.Model(&models.UserModel{}).
+			Where("role <> ?", ctype.PermissionDisableUser).
+			Pluck("id", &userIDList).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+	var list []models.NotificationModel
+	for _, userID := range userIDList {
+		list = append(list, models.NotificationModel{
+			RevUserID: userID,
+			Type:      notifyType,
+			Title:     title,
+			Content:   excerpt(content),
+		})
+	}
+	return len(list), s.Send(list...)
+}
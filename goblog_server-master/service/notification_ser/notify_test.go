@@ -0,0 +1,102 @@
+package notification_ser
+
+import (
+	"gvb_server/internal/testdb"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"strings"
+	"testing"
+)
+
+func TestNotification(t *testing.T) {
+	db := testdb.Open(t, &models.UserModel{}, &models.CommentModel{}, &models.NotificationModel{})
+
+	s := NotificationService{}
+	var userList []*models.UserModel
+	for _, nickName := range []string{"alice", "bob", "carol"} {
+		user := &models.UserModel{NickName: nickName, Role: ctype.PermissionUser}
+		db.Create(user)
+		userList = append(userList, user)
+	}
+	alice, bob, carol := userList[0], userList[1], userList[2]
+
+	parent := models.CommentModel{ArticleID: "a1", Content: "parent", UserID: alice.ID}
+	db.Create(&parent)
+	// bob回复alice，同时@了alice、carol和自己，alice只收到一条回复
+	reply := models.CommentModel{ArticleID: "a1", Content: "@alice @carol @bob @nobody 你好", UserID: bob.ID, ParentCommentID: &parent.ID}
+	db.Create(&reply)
+	if err := s.Comment(reply); err != nil {
+		t.Fatal(err)
+	}
+	aliceList, count, _ := s.List(alice.ID, 0, false, models.PageInfo{Page: 1, Limit: 10})
+	if count != 1 || aliceList[0].Type != ctype.NotifyReply || aliceList[0].SendUserNickName != "bob" {
+		t.Fatalf("alice: %+v", aliceList)
+	}
+	carolList, count, _ := s.List(carol.ID, ctype.NotifyMention, false, models.PageInfo{Page: 1, Limit: 10})
+	if count != 1 || carolList[0].CommentID != reply.ID {
+		t.Fatalf("carol: %+v", carolList)
+	}
+	if _, count, _ = s.List(bob.ID, 0, false, models.PageInfo{Page: 1}); count != 0 {
+		t.Fatalf("self notified: %d", count)
+	}
+
+	// 未读的点赞通知不重复
+	for i := 0; i < 3; i++ {
+		s.LikeComment(carol.ID, parent)
+	}
+	s.LikeComment(alice.ID, parent)
+	total, typeCount, _ := s.UnreadCount(alice.ID)
+	if total != 2 || typeCount[ctype.NotifyLike] != 1 {
+		t.Fatalf("unread: %d %v", total, typeCount)
+	}
+
+	n, _ := s.MarkRead(alice.ID, []uint{aliceList[0].ID})
+	total, _, _ = s.UnreadCount(alice.ID)
+	if n != 1 || total != 1 {
+		t.Fatalf("mark one: %d %d", n, total)
+	}
+	s.MarkRead(alice.ID, nil)
+	if total, _, _ = s.UnreadCount(alice.ID); total != 0 {
+		t.Fatalf("mark all: %d", total)
+	}
+	// 已读后再点赞会有新的通知
+	s.LikeComment(carol.ID, parent)
+	if _, count, _ = s.List(alice.ID, ctype.NotifyLike, false, models.PageInfo{Page: 1}); count != 2 {
+		t.Fatalf("like again: %d", count)
+	}
+
+	count2, _ := s.Broadcast("公告", "内容", nil)
+	if count2 != 3 {
+		t.Fatalf("broadcast: %d", count2)
+	}
+	count2, _ = s.Broadcast("系统", "内容", []uint{bob.ID})
+	bobList, _, _ := s.List(bob.ID, 0, true, models.PageInfo{Page: 1, Limit: 10})
+	if count2 != 1 || len(bobList) != 2 || bobList[0].Type != ctype.NotifySystem || bobList[1].Type != ctype.NotifyBroadcast {
+		t.Fatalf("bob: %+v", bobList)
+	}
+
+	body := DigestBody(*bob, bobList)
+	if !strings.Contains(body, "2 条未读通知") || !strings.Contains(body, "[系统通知] 系统") {
+		t.Fatalf("digest: %s", body)
+	}
+}
+
+func TestMentionUserList(t *testing.T) {
+	db := testdb.Open(t, &models.UserModel{}, &models.MessageModel{}, &models.NotificationModel{})
+
+	// 两个叫dave的用户，@dave不知道是谁；erin的用户名和昵称不同，两个都能@到；邮箱里的@不算
+	var userList []*models.UserModel
+	for _, name := range [][2]string{{"dave1", "dave"}, {"dave2", "dave"}, {"erin_x", "erin"}, {"frank", "frank"}} {
+		user := &models.UserModel{UserName: name[0], NickName: name[1], Role: ctype.PermissionUser}
+		db.Create(user)
+		userList = append(userList, user)
+	}
+	var idList []uint
+	for _, user := range mentionUserList("@dave @dave2 @erin @erin_x @nobody frank@example.com") {
+		idList = append(idList, user.ID)
+	}
+	if len(idList) != 2 || idList[0] != userList[1].ID || idList[1] != userList[2].ID {
+		t.Fatalf("mention: %v", idList)
+	}
+
+}
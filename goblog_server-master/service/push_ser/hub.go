@@ -0,0 +1,75 @@
+package push_ser
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// clientBuffer 每个连接缓存的事件数，客户端读得太慢时丢弃新的事件
+const clientBuffer = 16
+
+// Event 推给客户端的事件
+type Event struct {
+	Event string          `json:"event"` // 事件名，notification message unread broadcast
+	Data  json.RawMessage `json:"data"`
+}
+
+// Client 一个推送连接，同一个用户可以有多个
+type Client struct {
+	UserID uint
+	Events chan Event
+}
+
+// hub 本实例上的所有连接，按用户分组
+type hub struct {
+	sync.RWMutex
+	clientMap map[uint]map[*Client]struct{}
+}
+
+var localHub = hub{clientMap: map[uint]map[*Client]struct{}{}}
+
+// Subscribe 新建一个连接，断开时要调用Unsubscribe
+func Subscribe(userID uint) *Client {
+	client := &Client{UserID: userID, Events: make(chan Event, clientBuffer)}
+	localHub.Lock()
+	defer localHub.Unlock()
+	if localHub.clientMap[userID] == nil {
+		localHub.clientMap[userID] = map[*Client]struct{}{}
+	}
+	localHub.clientMap[userID][client] = struct{}{}
+	return client
+}
+
+// Unsubscribe 移除连接
+func Unsubscribe(client *Client) {
+	localHub.Lock()
+	defer localHub.Unlock()
+	clientList := localHub.clientMap[client.UserID]
+	delete(clientList, client)
+	if len(clientList) == 0 {
+		delete(localHub.clientMap, client.UserID)
+	}
+}
+
+// deliver 发给本实例上用户的所有连接，userID为0时发给所有连接
+func deliver(userID uint, event Event) {
+	localHub.RLock()
+	defer localHub.RUnlock()
+	if userID != 0 {
+		sendClients(localHub.clientMap[userID], event)
+		return
+	}
+	for _, clientList := range localHub.clientMap {
+		sendClients(clientList, event)
+	}
+}
+
+func sendClients(clientList map[*Client]struct{}, event Event) {
+	for client := range clientList {
+		select {
+		case client.Events <- event:
+		default:
+			// 缓冲满了说明客户端卡住了，不能阻塞其他连接
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package push_ser
+
+import (
+	"gvb_server/global"
+	"testing"
+)
+
+func TestPublishLocal(t *testing.T) {
+	global.Redis = nil
+	a1, a2, b := Subscribe(1), Subscribe(1), Subscribe(2)
+	defer Unsubscribe(a2)
+	defer Unsubscribe(b)
+
+	if err := Publish(1, "message", map[string]string{"content": "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, client := range []*Client{a1, a2} {
+		event := <-client.Events
+		if event.Event != "message" || string(event.Data) != `{"content":"hi"}` {
+			t.Fatalf("event: %s %s", event.Event, event.Data)
+		}
+	}
+	if len(b.Events) != 0 {
+		t.Fatal("other user received")
+	}
+
+	// 断开的连接收不到，userID为0发给所有连接
+	Unsubscribe(a1)
+	Publish(0, "broadcast", "公告")
+	if len(a1.Events) != 0 || len(a2.Events) != 1 || len(b.Events) != 1 {
+		t.Fatalf("broadcast: %d %d %d", len(a1.Events), len(a2.Events), len(b.Events))
+	}
+
+	// 缓冲满了丢弃，不阻塞
+	for i := 0; i < clientBuffer*2; i++ {
+		Publish(2, "unread", i)
+	}
+	if len(b.Events) != clientBuffer {
+		t.Fatalf("buffer: %d", len(b.Events))
+	}
+}
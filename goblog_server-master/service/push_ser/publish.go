@@ -0,0 +1,88 @@
+package push_ser
+
+import (
+	"encoding/json"
+	"gvb_server/global"
+	"gvb_server/models"
+)
+
+// channel 多个实例之间转发事件的redis频道
+const channel = "gvb_push"
+
+// message 频道里的消息
+type message struct {
+	UserID uint  `json:"user_id"` // 0 发给所有人
+	Event  Event `json:"event"`
+}
+
+// Unread 未读数
+type Unread struct {
+	Notification int64 `json:"notification"` // 未读通知数
+	Message      int64 `json:"message"`      // 未读私信数
+}
+
+// Publish 推送事件给用户，通过redis发给所有实例，没有连接redis时只发给本实例
+func Publish(userID uint, event string, data any) error {
+	byteData, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	msg := message{UserID: userID, Event: Event{Event: event, Data: byteData}}
+	if global.Redis == nil {
+		deliver(msg.UserID, msg.Event)
+		return nil
+	}
+	byteData, err = json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return global.Redis.Publish(channel, byteData).Err()
+}
+
+// GetUnread 用户的未读通知数和未读私信数
+func GetUnread(userID uint) (unread Unread) {
+	global.DB.Model(&models.NotificationModel{}).
+		Where("rev_user_id = ? and is_read = ?", userID, false).
+		Count(&unread.Notification)
+	global.DB.Model(&models.MessageModel{}).
+		Where("rev_user_id = ? and is_read = ?", userID, false).
+		Count(&unread.Message)
+	return unread
+}
+
+// PushUnread 推送最新的未读数，推送失败只记日志
+func PushUnread(userID uint) {
+	err := Publish(userID, "unread", GetUnread(userID))
+	if err != nil {
+		global.Log.Error(err)
+	}
+}
+
+// Push 推送事件和最新的未读数，推送失败只记日志
+func Push(userID uint, event string, data any) {
+	err := Publish(userID, event, data)
+	if err != nil {
+		global.Log.Error(err)
+		return
+	}
+	PushUnread(userID)
+}
+
+// Run 订阅redis频道，把其他实例发来的事件转给本实例的连接，启动时在协程里调用
+func Run() {
+	if global.Redis == nil {
+		return
+	}
+	pubsub := global.Redis.Subscribe(channel)
+	defer pubsub.Close()
+	// Channel断线会自动重连
+	for redisMsg := range pubsub.Channel() {
+		var msg message
+		err := json.Unmarshal([]byte(redisMsg.Payload), &msg)
+		if err != nil {
+			global.Log.Errorf("推送消息解析失败 %s", err)
+			continue
+		}
+		deliver(msg.UserID, msg.Event)
+	}
+}
@@ -0,0 +1,27 @@
+package redis_ser
+
+import (
+	"fmt"
+	"gvb_server/global"
+	"time"
+)
+
+const commentRatePrefix = "comment_rate" // 每个用户每分钟的评论数
+
+// AllowComment 用户这一分钟的评论数是否还没超过limit，调用一次记一次
+func AllowComment(userID uint, limit int) (bool, error) {
+	return allowPerMinute(fmt.Sprintf("%s_%d", commentRatePrefix, userID), limit)
+}
+
+// allowPerMinute 按分钟计数，这一分钟的次数是否还没超过limit，调用一次记一次
+func allowPerMinute(prefix string, limit int) (bool, error) {
+	key := fmt.Sprintf("%s_%d", prefix, time.Now().Unix()/60)
+	pipe := global.Redis.TxPipeline()
+	incr := pipe.Incr(key)
+	pipe.Expire(key, 2*time.Minute)
+	_, err := pipe.Exec()
+	if err != nil {
+		return false, err
+	}
+	return incr.Val() <= int64(limit), nil
+}
@@ -1,28 +1,33 @@
 package redis_ser
 
 import (
+	"github.com/go-redis/redis"
 	"gvb_server/global"
 	"strconv"
 )
 
 // CountDB redis构造方法
+// 计数存在 Index 这个hash里，有变化的id记录在 Index_dirty 集合中，同步时只处理变化的id
 type CountDB struct {
 	Index string // 索引前缀
 }
 
+// dirtyKey 有变化的id集合
+func (c CountDB) dirtyKey() string {
+	return c.Index + "_dirty"
+}
+
 // Set 设置某一个数据，重复执行，重复累加
 func (c CountDB) Set(id string) error {
-	num, _ := global.Redis.HGet(c.Index, id).Int()
-	num++
-	err := global.Redis.HSet(c.Index, id, num).Err()
-	return err
+	return c.SetCount(id, 1)
 }
 
 // SetCount 在原有基础上增加多少
 func (c CountDB) SetCount(id string, num int) error {
-	oldNum, _ := global.Redis.HGet(c.Index, id).Int()
-	newNum := oldNum + num
-	err := global.Redis.HSet(c.Index, id, newNum).Err()
+	pipe := global.Redis.TxPipeline()
+	pipe.HIncrBy(c.Index, id, int64(num))
+	pipe.SAdd(c.dirtyKey(), id)
+	_, err := pipe.Exec()
 	return err
 }
 
@@ -43,7 +48,57 @@ func (c CountDB) GetInfo() map[string]int {
 	return diggInfo
 }
 
+// takeScript 从dirty集合里取出一批id，读取并删除对应的计数，整个过程是原子的
+var takeScript = redis.NewScript(`
+redis.replicate_commands()
+local ids = redis.call('SPOP', KEYS[2], ARGV[1])
+local result = {}
+for _, id in ipairs(ids) do
+  local num = redis.call('HGET', KEYS[1], id)
+  if num then
+    redis.call('HDEL', KEYS[1], id)
+    table.insert(result, id)
+    table.insert(result, num)
+  end
+end
+return result
+`)
+
+// TakeDirty 取出最多size个有变化的计数并清零，没有变化时返回空
+// 取出后同步失败要用SetCount加回去
+func (c CountDB) TakeDirty(size int) (map[string]int, error) {
+	val, err := takeScript.Run(global.Redis, []string{c.Index, c.dirtyKey()}, size).Result()
+	if err != nil {
+		return nil, err
+	}
+	list, _ := val.([]any)
+	var countMap = map[string]int{}
+	for i := 0; i+1 < len(list); i += 2 {
+		id, _ := list[i].(string)
+		numStr, _ := list[i+1].(string)
+		num, _ := strconv.Atoi(numStr)
+		if num == 0 {
+			continue
+		}
+		countMap[id] = num
+	}
+	return countMap, nil
+}
+
+// MarkAllDirty 把hash里已有的id都标记为有变化，兼容升级前没有dirty集合的数据
+func (c CountDB) MarkAllDirty() error {
+	keys := global.Redis.HKeys(c.Index).Val()
+	if len(keys) == 0 {
+		return nil
+	}
+	var members []any
+	for _, key := range keys {
+		members = append(members, key)
+	}
+	return global.Redis.SAdd(c.dirtyKey(), members...).Err()
+}
+
 // Clear 删除数据
 func (c CountDB) Clear() {
-	global.Redis.Del(c.Index)
+	global.Redis.Del(c.Index, c.dirtyKey())
 }
@@ -0,0 +1,39 @@
+package redis_ser
+
+import (
+	"gvb_server/global"
+	"time"
+)
+
+const sitemapIndex = "sitemap"
+
+// SetSitemap 缓存生成好的sitemap，key是页码，index为sitemap索引
+func SetSitemap(pageMap map[string][]byte) error {
+	values := map[string]any{}
+	for key, data := range pageMap {
+		values[key] = data
+	}
+	err := global.Redis.HMSet(sitemapIndex, values).Err()
+	if err != nil {
+		return err
+	}
+	// 文章增删改时会主动清除，过期时间兜底
+	return global.Redis.Expire(sitemapIndex, 24*time.Hour).Err()
+}
+
+// GetSitemap 取缓存的sitemap，没有缓存ok为false
+func GetSitemap(key string) (data []byte, ok bool) {
+	if global.Redis.Exists(sitemapIndex).Val() == 0 {
+		return nil, false
+	}
+	data, err := global.Redis.HGet(sitemapIndex, key).Bytes()
+	if err != nil {
+		return nil, true
+	}
+	return data, true
+}
+
+// ClearSitemap 文章变化后清除sitemap缓存
+func ClearSitemap() {
+	global.Redis.Del(sitemapIndex)
+}
@@ -0,0 +1,347 @@
+package redis_ser
+
+import (
+	"fmt"
+	"github.com/go-redis/redis"
+	"gvb_server/global"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	articleLookDayPrefix  = "article_look_day"  // 每天的浏览量 hash 文章id -> 浏览量
+	articleLookHourPrefix = "article_look_hour" // 每小时的浏览量 hash 文章id -> 浏览量
+	articleUVPrefix       = "article_uv"        // 文章的访客 HyperLogLog
+	articleReferrerPrefix = "article_referrer"  // 每天的来源 zset 来源域名 -> 次数
+	articleKeywordPrefix  = "article_keyword"   // 每天的搜索关键字 zset 关键字 -> 次数
+	articleReadPrefix     = "article_read"      // 每天的阅读时长分布 hash 区间 -> 次数
+	articleReadSumPrefix  = "article_read_sum"  // 每天的阅读总时长 hash seconds、count
+	articleReadRatePrefix = "article_read_rate" // 每个访客每分钟上报阅读时长的次数
+)
+
+// 按天的数据保留的时间
+const visitorRetention = 400 * 24 * time.Hour
+
+// 按小时的数据保留的时间
+const visitorHourRetention = 32 * 24 * time.Hour
+
+// 来源、关键字是前端传过来的，限制长度和每天每篇文章保留的个数，只留次数最多的
+const (
+	maxSourceLength  = 64
+	maxSourceMembers = 100
+)
+
+const (
+	dayFormat  = "2006-01-02"
+	hourFormat = "2006-01-02-15"
+)
+
+// ViewSource 浏览的来源
+type ViewSource struct {
+	Referrer string // 来源域名，直接访问为空
+	Keyword  string // 从搜索引擎或站内搜索过来时的关键字
+}
+
+// DayView 某一天的浏览量和访客数
+type DayView struct {
+	Date  string `json:"date"`
+	Views int    `json:"views"` // 浏览量
+	UV    int    `json:"uv"`    // 访客数
+}
+
+func articleLookDayKey(day string) string {
+	return fmt.Sprintf("%s_%s", articleLookDayPrefix, day)
+}
+
+func articleUVKey(id string) string {
+	return fmt.Sprintf("%s_%s", articleUVPrefix, id)
+}
+
+// articleBucketUVKey 文章按天或按小时的访客，bucket为dayFormat或hourFormat格式的时间
+func articleBucketUVKey(id, bucket string) string {
+	return fmt.Sprintf("%s_%s_%s", articleUVPrefix, id, bucket)
+}
+
+func articleLookHourKey(hour string) string {
+	return fmt.Sprintf("%s_%s", articleLookHourPrefix, hour)
+}
+
+func articleDayKey(prefix, id, day string) string {
+	return fmt.Sprintf("%s_%s_%s", prefix, id, day)
+}
+
+// AddArticleView 记录一次文章浏览，visitor 为登录用户id或ip加ua生成的指纹
+// 浏览量累加到article_look由定时任务同步，访客用HyperLogLog去重，同时按天、按小时分桶
+func AddArticleView(id, visitor string, source ViewSource) error {
+	err := NewArticleLook().Set(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	day := now.Format(dayFormat)
+	hour := now.Format(hourFormat)
+	pipe := global.Redis.Pipeline()
+	pipe.HIncrBy(articleLookDayKey(day), id, 1)
+	pipe.Expire(articleLookDayKey(day), visitorRetention)
+	pipe.HIncrBy(articleLookHourKey(hour), id, 1)
+	pipe.Expire(articleLookHourKey(hour), visitorHourRetention)
+	pipe.PFAdd(articleUVKey(id), visitor)
+	pipe.PFAdd(articleBucketUVKey(id, day), visitor)
+	pipe.Expire(articleBucketUVKey(id, day), visitorRetention)
+	pipe.PFAdd(articleBucketUVKey(id, hour), visitor)
+	pipe.Expire(articleBucketUVKey(id, hour), visitorHourRetention)
+	if source.Referrer != "" {
+		addSource(pipe, articleDayKey(articleReferrerPrefix, id, day), source.Referrer)
+	}
+	if source.Keyword != "" {
+		addSource(pipe, articleDayKey(articleKeywordPrefix, id, day), source.Keyword)
+	}
+	_, err = pipe.Exec()
+	return err
+}
+
+// addSource 来源、关键字计数，超长的截断，超过个数的去掉次数最少的
+func addSource(pipe redis.Pipeliner, key, member string) {
+	if runes := []rune(member); len(runes) > maxSourceLength {
+		member = string(runes[:maxSourceLength])
+	}
+	pipe.ZIncrBy(key, 1, member)
+	pipe.ZRemRangeByRank(key, 0, -maxSourceMembers-1)
+	pipe.Expire(key, visitorRetention)
+}
+
+// AllowArticleReading 访客这一分钟上报阅读时长的次数是否还没超过limit，调用一次记一次
+func AllowArticleReading(visitor string, limit int) (bool, error) {
+	return allowPerMinute(fmt.Sprintf("%s_%s", articleReadRatePrefix, visitor), limit)
+}
+
+// 阅读时长的区间，单位秒
+var readingBucketList = []struct {
+	Label string
+	Max   int
+}{
+	{"0-10s", 10},
+	{"10-30s", 30},
+	{"30s-1m", 60},
+	{"1-3m", 180},
+	{"3-5m", 300},
+	{"5-10m", 600},
+	{"10m+", 0},
+}
+
+// readingBucket 阅读时长所在的区间
+func readingBucket(seconds int) string {
+	for _, bucket := range readingBucketList {
+		if bucket.Max == 0 || seconds < bucket.Max {
+			return bucket.Label
+		}
+	}
+	return ""
+}
+
+// AddArticleReading 记录一次阅读时长
+func AddArticleReading(id string, seconds int) error {
+	day := time.Now().Format(dayFormat)
+	readKey := articleDayKey(articleReadPrefix, id, day)
+	sumKey := articleDayKey(articleReadSumPrefix, id, day)
+	pipe := global.Redis.Pipeline()
+	pipe.HIncrBy(readKey, readingBucket(seconds), 1)
+	pipe.Expire(readKey, visitorRetention)
+	pipe.HIncrBy(sumKey, "seconds", int64(seconds))
+	pipe.HIncrBy(sumKey, "count", 1)
+	pipe.Expire(sumKey, visitorRetention)
+	_, err := pipe.Exec()
+	return err
+}
+
+// GetArticleUV 文章的访客数
+func GetArticleUV(id string) int {
+	return int(global.Redis.PFCount(articleUVKey(id)).Val())
+}
+
+// GetArticleUVMap 一批文章的访客数
+func GetArticleUVMap(idList []string) map[string]int {
+	var uvMap = map[string]int{}
+	if len(idList) == 0 {
+		return uvMap
+	}
+	pipe := global.Redis.Pipeline()
+	var cmdList []*redis.IntCmd
+	for _, id := range idList {
+		cmdList = append(cmdList, pipe.PFCount(articleUVKey(id)))
+	}
+	_, _ = pipe.Exec()
+	for i, id := range idList {
+		uvMap[id] = int(cmdList[i].Val())
+	}
+	return uvMap
+}
+
+// GetArticleDayViews 文章在一段时间内每天的浏览量和访客数，没有数据的天为0
+func GetArticleDayViews(id string, start, end time.Time) []DayView {
+	dayList := dayList(start, end)
+	pipe := global.Redis.Pipeline()
+	var viewCmdList []*redis.StringCmd
+	var uvCmdList []*redis.IntCmd
+	for _, day := range dayList {
+		viewCmdList = append(viewCmdList, pipe.HGet(articleLookDayKey(day), id))
+		uvCmdList = append(uvCmdList, pipe.PFCount(articleBucketUVKey(id, day)))
+	}
+	_, _ = pipe.Exec()
+
+	var list = make([]DayView, 0, len(dayList))
+	for i, day := range dayList {
+		views, _ := viewCmdList[i].Int()
+		list = append(list, DayView{
+			Date:  day,
+			Views: views,
+			UV:    int(uvCmdList[i].Val()),
+		})
+	}
+	return list
+}
+
+// RemoveArticleUV 文章删除后清掉总访客数，按天的数据等过期
+func RemoveArticleUV(idList []string) {
+	var keys []string
+	for _, id := range idList {
+		keys = append(keys, articleUVKey(id))
+	}
+	if len(keys) > 0 {
+		global.Redis.Del(keys...)
+	}
+}
+
+// KeyCount 来源、关键字、阅读时长区间的计数
+type KeyCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// dayList start到end的每一天
+func dayList(start, end time.Time) (list []string) {
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		list = append(list, day.Format(dayFormat))
+	}
+	return list
+}
+
+// GetArticleViewSeries 按小时、天或周统计浏览量和访客数，timeList为每个区间的起点
+// 按周统计时访客数是7天HyperLogLog的并集，同一个人一周内只算一次
+func GetArticleViewSeries(id string, timeList []time.Time, granularity string) (views, uv []int) {
+	pipe := global.Redis.Pipeline()
+	var viewCmdList [][]*redis.StringCmd
+	var uvCmdList []*redis.IntCmd
+	for _, t := range timeList {
+		var viewKeyList, uvKeyList []string
+		switch granularity {
+		case "hour":
+			hour := t.Format(hourFormat)
+			viewKeyList = []string{articleLookHourKey(hour)}
+			uvKeyList = []string{articleBucketUVKey(id, hour)}
+		case "week":
+			for _, day := range dayList(t, t.AddDate(0, 0, 6)) {
+				viewKeyList = append(viewKeyList, articleLookDayKey(day))
+				uvKeyList = append(uvKeyList, articleBucketUVKey(id, day))
+			}
+		default:
+			day := t.Format(dayFormat)
+			viewKeyList = []string{articleLookDayKey(day)}
+			uvKeyList = []string{articleBucketUVKey(id, day)}
+		}
+		var cmdList []*redis.StringCmd
+		for _, key := range viewKeyList {
+			cmdList = append(cmdList, pipe.HGet(key, id))
+		}
+		viewCmdList = append(viewCmdList, cmdList)
+		uvCmdList = append(uvCmdList, pipe.PFCount(uvKeyList...))
+	}
+	_, _ = pipe.Exec()
+
+	views = make([]int, len(timeList))
+	uv = make([]int, len(timeList))
+	for i := range timeList {
+		for _, cmd := range viewCmdList[i] {
+			num, _ := cmd.Int()
+			views[i] += num
+		}
+		uv[i] = int(uvCmdList[i].Val())
+	}
+	return views, uv
+}
+
+// getArticleTop 合并每天的zset，取前size个
+func getArticleTop(prefix, id string, start, end time.Time, size int) []KeyCount {
+	pipe := global.Redis.Pipeline()
+	var cmdList []*redis.ZSliceCmd
+	for _, day := range dayList(start, end) {
+		cmdList = append(cmdList, pipe.ZRangeWithScores(articleDayKey(prefix, id, day), 0, -1))
+	}
+	_, _ = pipe.Exec()
+	var countMap = map[string]int{}
+	for _, cmd := range cmdList {
+		for _, z := range cmd.Val() {
+			key, _ := z.Member.(string)
+			countMap[key] += int(z.Score)
+		}
+	}
+	var list = make([]KeyCount, 0, len(countMap))
+	for key, count := range countMap {
+		list = append(list, KeyCount{Key: key, Count: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Key < list[j].Key
+	})
+	if len(list) > size {
+		list = list[:size]
+	}
+	return list
+}
+
+// GetArticleReferrers 来源域名排行
+func GetArticleReferrers(id string, start, end time.Time, size int) []KeyCount {
+	return getArticleTop(articleReferrerPrefix, id, start, end, size)
+}
+
+// GetArticleKeywords 搜索关键字排行
+func GetArticleKeywords(id string, start, end time.Time, size int) []KeyCount {
+	return getArticleTop(articleKeywordPrefix, id, start, end, size)
+}
+
+// GetArticleReading 阅读时长分布和平均阅读时长（秒）
+func GetArticleReading(id string, start, end time.Time) (list []KeyCount, avgSeconds int) {
+	pipe := global.Redis.Pipeline()
+	var readCmdList, sumCmdList []*redis.StringStringMapCmd
+	for _, day := range dayList(start, end) {
+		readCmdList = append(readCmdList, pipe.HGetAll(articleDayKey(articleReadPrefix, id, day)))
+		sumCmdList = append(sumCmdList, pipe.HGetAll(articleDayKey(articleReadSumPrefix, id, day)))
+	}
+	_, _ = pipe.Exec()
+
+	var countMap = map[string]int{}
+	for _, cmd := range readCmdList {
+		for key, val := range cmd.Val() {
+			num, _ := strconv.Atoi(val)
+			countMap[key] += num
+		}
+	}
+	var seconds, count int
+	for _, cmd := range sumCmdList {
+		val := cmd.Val()
+		num, _ := strconv.Atoi(val["seconds"])
+		seconds += num
+		num, _ = strconv.Atoi(val["count"])
+		count += num
+	}
+	for _, bucket := range readingBucketList {
+		list = append(list, KeyCount{Key: bucket.Label, Count: countMap[bucket.Label]})
+	}
+	if count > 0 {
+		avgSeconds = seconds / count
+	}
+	return list, avgSeconds
+}
@@ -0,0 +1,92 @@
+package seed_ser
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+var tagPool = []string{"Go", "Gin", "Gorm", "Vue", "MySQL", "Redis", "Elasticsearch", "Docker", "Linux", "算法", "前端", "后端"}
+
+var categoryPool = []string{"后端", "前端", "运维", "随笔", "读书笔记"}
+
+var topicPool = []string{
+	"并发编程", "接口设计", "缓存一致性", "全文搜索", "容器部署", "日志收集",
+	"性能优化", "单元测试", "数据库索引", "中间件", "消息队列", "权限控制",
+}
+
+var titleFormatList = []string{
+	"%s入门实践", "聊聊%s", "%s踩坑记录", "一次%s的排查过程", "%s的几种写法", "从零开始学%s",
+}
+
+var sentencePool = []string{
+	"这个问题在项目上线之后才暴露出来。",
+	"先看一下最简单的实现，再逐步完善。",
+	"文档里并没有写清楚这一点，只能去翻源码。",
+	"在并发量不大的时候，这样写完全没有问题。",
+	"换一种思路，把状态放到外面管理会清晰很多。",
+	"需要注意的是，错误一定要向上返回，不要吞掉。",
+	"压测之后发现瓶颈并不在数据库，而是在序列化上。",
+	"这里用一个简单的例子说明。",
+	"如果有更好的做法，欢迎在评论区交流。",
+	"最后把配置抽出来，方便在不同环境下切换。",
+}
+
+var commentPool = []string{
+	"写得很清楚，收藏了", "请问这个在生产环境用过吗？", "学到了，感谢分享", "第二段的代码是不是少了错误处理？",
+	"我也遇到过同样的问题", "有没有完整的示例仓库", "同问", "已经解决了，谢谢", "mark一下", "期待下一篇",
+}
+
+var codeList = []string{
+	"func main() {\n\tfmt.Println(\"hello gvb\")\n}",
+	"r := gin.Default()\nr.GET(\"/ping\", func(c *gin.Context) {\n\tc.JSON(200, gin.H{\"msg\": \"pong\"})\n})",
+	"docker compose up -d",
+	"SELECT id, title FROM article_models ORDER BY created_at DESC LIMIT 10;",
+}
+
+// randomItem 随机取一个
+func randomItem(r *rand.Rand, list []string) string {
+	return list[r.Intn(len(list))]
+}
+
+// randomParagraph 随机拼几句话
+func randomParagraph(r *rand.Rand, min, max int) string {
+	var sb strings.Builder
+	count := min + r.Intn(max-min+1)
+	for i := 0; i < count; i++ {
+		sb.WriteString(randomItem(r, sentencePool))
+	}
+	return sb.String()
+}
+
+// randomTitle 随机标题
+func randomTitle(r *rand.Rand) string {
+	return fmt.Sprintf(randomItem(r, titleFormatList), randomItem(r, topicPool))
+}
+
+// randomTags 随机1到3个不重复的标签
+func randomTags(r *rand.Rand) []string {
+	count := 1 + r.Intn(3)
+	var tags []string
+	for _, i := range r.Perm(len(tagPool))[:count] {
+		tags = append(tags, tagPool[i])
+	}
+	return tags
+}
+
+// randomMarkdown 随机生成一篇markdown正文，有标题、段落、列表、代码块和引用
+func randomMarkdown(r *rand.Rand, title string) string {
+	var sb strings.Builder
+	sb.WriteString("## 背景\n\n")
+	sb.WriteString(randomParagraph(r, 2, 4) + "\n\n")
+	sb.WriteString("## " + title + "\n\n")
+	sb.WriteString(randomParagraph(r, 3, 6) + "\n\n")
+	for i := 0; i < 2+r.Intn(3); i++ {
+		sb.WriteString("- " + randomItem(r, sentencePool) + "\n")
+	}
+	sb.WriteString("\n```\n" + randomItem(r, codeList) + "\n```\n\n")
+	sb.WriteString("> " + randomItem(r, sentencePool) + "\n\n")
+	sb.WriteString("## 总结\n\n")
+	sb.WriteString(randomParagraph(r, 2, 3) + "\n")
+	return sb.String()
+}
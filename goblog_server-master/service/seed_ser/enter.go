@@ -0,0 +1,4 @@
+package seed_ser
+
+type SeedService struct {
+}
@@ -0,0 +1,66 @@
+package seed_ser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Option 生成演示数据的数量
+type Option struct {
+	Users    int // 用户数
+	Articles int // 文章数
+	Comments int // 每篇文章最多的根评论数，每条根评论下会随机生成多层回复
+	Messages int // 用户之间的消息数
+	Chats    int // 群聊记录数
+}
+
+// DefaultOption 默认数量
+var DefaultOption = Option{
+	Users:    10,
+	Articles: 20,
+	Comments: 5,
+	Messages: 30,
+	Chats:    50,
+}
+
+// ParseOption 解析 -seed 的参数
+// 只写一个数字表示用户数，其余按默认值；也可以写成 users=10,articles=20,comments=5,messages=30,chats=50
+func ParseOption(value string) (Option, error) {
+	option := DefaultOption
+	value = strings.TrimSpace(value)
+	if value == "" || value == "default" {
+		return option, nil
+	}
+	if num, err := strconv.Atoi(value); err == nil {
+		option.Users = num
+		return option, option.check()
+	}
+	var fieldMap = map[string]*int{
+		"users":    &option.Users,
+		"articles": &option.Articles,
+		"comments": &option.Comments,
+		"messages": &option.Messages,
+		"chats":    &option.Chats,
+	}
+	for _, item := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(strings.TrimSpace(item), "=")
+		field, has := fieldMap[key]
+		if !ok || !has {
+			return option, fmt.Errorf("参数错误 %s", item)
+		}
+		num, err := strconv.Atoi(val)
+		if err != nil {
+			return option, fmt.Errorf("参数错误 %s", item)
+		}
+		*field = num
+	}
+	return option, option.check()
+}
+
+func (o Option) check() error {
+	if o.Users <= 0 || o.Articles < 0 || o.Comments < 0 || o.Messages < 0 || o.Chats < 0 {
+		return fmt.Errorf("用户数必须大于0，其他数量不能小于0")
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package seed_ser
+
+import (
+	"errors"
+	"fmt"
+	"github.com/DanPlayer/randomname"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/models/ctype"
+	"gvb_server/service/article_ser"
+	"gvb_server/service/comment_ser"
+	"gvb_server/service/es_ser"
+	"gvb_server/service/user_ser"
+	"math/rand"
+	"time"
+)
+
+// 演示用户的密码
+const Password = "123456"
+
+// 评论最多的层数
+const maxCommentDepth = 3
+
+// Result 实际生成的数量
+type Result struct {
+	Users    int
+	Tags     int
+	Articles int
+	Comments int
+	Messages int
+	Chats    int
+}
+
+// Seed 生成演示数据：用户、标签、文章、多层评论、消息和群聊记录，文章会同时写入文章索引和全文搜索索引
+func (SeedService) Seed(option Option) (result Result, err error) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	userList := seedUsers(r, option.Users)
+	result.Users = len(userList)
+	if len(userList) == 0 {
+		return result, errors.New("没有创建成功的用户")
+	}
+
+	result.Tags, err = seedTags()
+	if err != nil {
+		return result, err
+	}
+
+	articleList := seedArticles(r, option.Articles, userList)
+	result.Articles = len(articleList)
+
+	for _, article := range articleList {
+		count, err := seedComments(r, article, option.Comments, userList)
+		result.Comments += count
+		if err != nil {
+			return result, err
+		}
+	}
+
+	result.Messages, err = seedMessages(r, option.Messages, userList)
+	if err != nil {
+		return result, err
+	}
+	result.Chats, err = seedChats(r, option.Chats)
+	return result, err
+}
+
+// seedUsers 用随机昵称创建普通用户
+func seedUsers(r *rand.Rand, count int) (userList []models.UserModel) {
+	var userNameList []string
+	for i := 0; i < count; i++ {
+		userName := fmt.Sprintf("demo%06d", r.Intn(1000000))
+		err := user_ser.UserService{}.CreateUser(userName, randomname.GenerateName(), Password, ctype.PermissionUser, userName+"@example.com", "127.0.0.1")
+		if err != nil {
+			global.Log.Warnf("创建用户 %s 失败 %s", userName, err)
+			continue
+		}
+		userNameList = append(userNameList, userName)
+	}
+	if len(userNameList) == 0 {
+		return nil
+	}
+	global.DB.Find(&userList, "user_name in ?", userNameList)
+	return userList
+}
+
+// seedTags 标签池里没有的标签
+func seedTags() (count int, err error) {
+	for _, title := range tagPool {
+		result := global.DB.Where(models.TagModel{Title: title}).FirstOrCreate(&models.TagModel{})
+		if result.Error != nil {
+			return count, result.Error
+		}
+		count += int(result.RowsAffected)
+	}
+	return count, nil
+}
+
+// seedArticles 随机作者、标签和最近90天内的发布时间
+func seedArticles(r *rand.Rand, count int, userList []models.UserModel) (articleList []models.ArticleModel) {
+	var bannerList []models.BannerModel
+	global.DB.Select("id", "path").Find(&bannerList)
+
+	repository := article_ser.NewRepository()
+	articleService := article_ser.ArticleService{}
+	format := "2006-01-02 15:04:05"
+	for i := 0; i < count; i++ {
+		author := userList[r.Intn(len(userList))]
+		title := randomTitle(r)
+		if repository.IsExistTitle(title) {
+			title = fmt.Sprintf("%s（%d）", title, r.Intn(10000))
+		}
+		content, text, _ := articleService.FilterXSS(randomMarkdown(r, title))
+		createdAt := time.Now().Add(-time.Duration(r.Int63n(int64(90 * 24 * time.Hour)))).Format(format)
+		article := models.ArticleModel{
+			CreatedAt:    createdAt,
+			UpdatedAt:    createdAt,
+			Title:        title,
+			Keyword:      title,
+			Abstract:     articleService.GetAbstract(text),
+			Content:      content,
+			UserID:       author.ID,
+			UserNickName: author.NickName,
+			UserAvatar:   author.Avatar,
+			Category:     randomItem(r, categoryPool),
+			Tags:         randomTags(r),
+			Status:       ctype.ArticlePublished,
+			PublishAt:    createdAt,
+		}
+		if len(bannerList) > 0 {
+			banner := bannerList[r.Intn(len(bannerList))]
+			article.BannerID, article.BannerUrl = banner.ID, banner.Path
+		}
+		err := repository.Create(&article)
+		if err != nil {
+			global.Log.Errorf("创建文章 %s 失败 %s", title, err)
+			continue
+		}
+		_, err = articleService.CreateRevision(article, author.ID, author.NickName, "演示数据")
+		if err != nil {
+			global.Log.Error(err)
+		}
+		es_ser.AsyncArticleByFullText(article.ID, article.Title, article.Content)
+		articleList = append(articleList, article)
+	}
+	return articleList
+}
+
+// seedComments 给文章生成最多max条根评论，每条根评论下随机生成回复，文章的评论数直接累加
+func seedComments(r *rand.Rand, article models.ArticleModel, max int, userList []models.UserModel) (count int, err error) {
+	if max <= 0 {
+		return 0, nil
+	}
+	createdAt, _ := time.ParseInLocation("2006-01-02 15:04:05", article.CreatedAt, time.Local)
+	for i := r.Intn(max + 1); i > 0; i-- {
+		n, err := seedCommentTree(r, article.ID, nil, createdAt, 1, userList)
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	_, err = article_ser.NewRepository().AddCounts(map[string]article_ser.CountDelta{
+		article.ID: {CommentCount: count},
+	})
+	return count, err
+}
+
+// seedCommentTree 生成一条评论和它下面的回复，返回评论总数
+func seedCommentTree(r *rand.Rand, articleID string, parent *models.CommentModel, after time.Time, depth int, userList []models.UserModel) (int, error) {
+	createdAt := after.Add(time.Duration(r.Int63n(int64(48 * time.Hour))))
+	if createdAt.After(time.Now()) {
+		createdAt = time.Now()
+	}
+	comment := models.CommentModel{
+		MODEL:     models.MODEL{CreatedAt: createdAt, UpdatedAt: createdAt},
+		Content:   randomItem(r, commentPool),
+		ArticleID: articleID,
+		UserID:    userList[r.Intn(len(userList))].ID,
+	}
+	err := comment_ser.CommentService{}.Create(&comment, parent)
+	if err != nil {
+		return 0, err
+	}
+	if depth >= maxCommentDepth {
+		return 1, nil
+	}
+	count := 1
+	for i := r.Intn(3); i > 0; i-- {
+		n, err := seedCommentTree(r, articleID, &comment, createdAt, depth+1, userList)
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// seedMessages 用户之间随机发消息
+func seedMessages(r *rand.Rand, count int, userList []models.UserModel) (int, error) {
+	if len(userList) < 2 || count == 0 {
+		return 0, nil
+	}
+	var messageList []models.MessageModel
+	for i := 0; i < count; i++ {
+		perm := r.Perm(len(userList))
+		send, rev := userList[perm[0]], userList[perm[1]]
+		createdAt := time.Now().Add(-time.Duration(r.Int63n(int64(30 * 24 * time.Hour))))
+		messageList = append(messageList, models.MessageModel{
+			MODEL:            models.MODEL{CreatedAt: createdAt, UpdatedAt: createdAt},
+			SendUserID:       send.ID,
+			SendUserNickName: send.NickName,
+			SendUserAvatar:   send.Avatar,
+			RevUserID:        rev.ID,
+			RevUserNickName:  rev.NickName,
+			RevUserAvatar:    rev.Avatar,
+			IsRead:           r.Intn(2) == 0,
+			Content:          randomParagraph(r, 1, 2),
+		})
+	}
+	err := global.DB.CreateInBatches(&messageList, 100).Error
+	if err != nil {
+		return 0, err
+	}
+	return len(messageList), nil
+}
+
+// seedChats 最近7天的群聊记录，昵称和头像的规则和聊天室一致
+func seedChats(r *rand.Rand, count int) (int, error) {
+	if count == 0 {
+		return 0, nil
+	}
+	var chatList []models.ChatModel
+	for i := 0; i < count; i++ {
+		nickName := randomname.GenerateName()
+		createdAt := time.Now().Add(-time.Duration(r.Int63n(int64(7 * 24 * time.Hour))))
+		chatList = append(chatList, models.ChatModel{
+			MODEL:    models.MODEL{CreatedAt: createdAt, UpdatedAt: createdAt},
+			NickName: nickName,
+			Avatar:   fmt.Sprintf("./uploads/chat_avatar/%s.png", string([]rune(nickName)[0])),
+			Content:  randomItem(r, commentPool),
+			IP:       "127.0.0.1",
+			Addr:     "内网地址",
+			ISGroup:  true,
+			MsgType:  ctype.TextMsg,
+		})
+	}
+	err := global.DB.CreateInBatches(&chatList, 100).Error
+	if err != nil {
+		return 0, err
+	}
+	return len(chatList), nil
+}
@@ -0,0 +1,56 @@
+package seed_ser
+
+import (
+	"gvb_server/config"
+	"gvb_server/global"
+	"gvb_server/internal/testdb"
+	"gvb_server/migrations"
+	"gvb_server/models"
+	"testing"
+)
+
+func TestParseOption(t *testing.T) {
+	option, err := ParseOption("users=3,comments=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if option.Users != 3 || option.Comments != 0 || option.Articles != DefaultOption.Articles {
+		t.Fatalf("got %+v", option)
+	}
+	if _, err = ParseOption("posts=3"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestSeedSqlite(t *testing.T) {
+	db := testdb.Open(t)
+	global.Config = &config.Config{System: config.System{ArticleStorage: "mysql"}}
+	_, err := migrations.Migrate(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := SeedService{}.Seed(Option{Users: 3, Articles: 4, Comments: 3, Messages: 5, Chats: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Users != 3 || result.Articles != 4 || result.Messages != 5 || result.Chats != 5 {
+		t.Fatalf("got %+v", result)
+	}
+
+	// 文章的评论数和评论表一致
+	var commentCount, articleCommentCount int64
+	db.Model(&models.CommentModel{}).Count(&commentCount)
+	db.Model(&models.ArticleModel{}).Select("coalesce(sum(comment_count), 0)").Scan(&articleCommentCount)
+	if int(commentCount) != result.Comments || commentCount != articleCommentCount {
+		t.Fatalf("comments %d, result %d, article comment_count %d", commentCount, result.Comments, articleCommentCount)
+	}
+
+	// 父评论的回复数和回复一致
+	var replyCount, sumReplyCount int64
+	db.Model(&models.CommentModel{}).Where("parent_comment_id is not null").Count(&replyCount)
+	db.Model(&models.CommentModel{}).Select("coalesce(sum(comment_count), 0)").Scan(&sumReplyCount)
+	if replyCount != sumReplyCount {
+		t.Fatalf("replies %d, sum comment_count %d", replyCount, sumReplyCount)
+	}
+}
@@ -0,0 +1,128 @@
+package static_ser
+
+import (
+	"bytes"
+	"encoding/json"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/service/feed_ser"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+)
+
+var articlePageTemplate = template.Must(template.ParseFS(templateFS, "templates/meta.html"))
+
+// articleMeta 文章页的分享信息
+type articleMeta struct {
+	SiteName    string
+	Home        string
+	Title       string
+	Description string
+	URL         string
+	Image       string
+	Author      string
+	Tags        []string
+	Published   string
+	Modified    string
+	JSONLD      template.JS
+}
+
+// ArticlePage 文章页html，带og、twitter card、canonical和JSON-LD
+// 配置了前端的index.html时把meta注入进去，由前端接管渲染；没有配置时返回一个简单的页面
+func (StaticService) ArticlePage(article models.ArticleModel) ([]byte, error) {
+	meta := newArticleMeta(article)
+
+	spaIndex := global.Config.System.SpaIndex
+	if spaIndex == "" {
+		var buf bytes.Buffer
+		err := articlePageTemplate.ExecuteTemplate(&buf, "shell", meta)
+		return buf.Bytes(), err
+	}
+
+	index, err := os.ReadFile(spaIndex)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = articlePageTemplate.ExecuteTemplate(&buf, "meta", meta)
+	if err != nil {
+		return nil, err
+	}
+	return injectHead(index, buf.Bytes()), nil
+}
+
+// injectHead 去掉前端自带的title，把meta放到</head>前
+func injectHead(index, meta []byte) []byte {
+	html := string(index)
+	if start := strings.Index(html, "<title>"); start != -1 {
+		if end := strings.Index(html[start:], "</title>"); end != -1 {
+			html = html[:start] + html[start+end+len("</title>"):]
+		}
+	}
+	i := strings.Index(html, "</head>")
+	if i == -1 {
+		return []byte(string(meta) + html)
+	}
+	return []byte(html[:i] + string(meta) + html[i:])
+}
+
+func newArticleMeta(article models.ArticleModel) articleMeta {
+	site := global.Config.SiteInfo
+	meta := articleMeta{
+		SiteName:    site.Title,
+		Home:        feed_ser.SiteURL() + "/",
+		Title:       article.Title,
+		Description: article.Abstract,
+		URL:         feed_ser.ArticleURL(article.ID),
+		Image:       absoluteURL(article.BannerUrl),
+		Author:      article.UserNickName,
+		Tags:        article.Tags,
+		Published:   isoTime(article.CreatedAt),
+		Modified:    isoTime(article.UpdatedAt),
+	}
+	if meta.Modified == "" {
+		meta.Modified = meta.Published
+	}
+
+	jsonLD := map[string]any{
+		"@context":         "https://schema.org",
+		"@type":            "BlogPosting",
+		"headline":         meta.Title,
+		"description":      meta.Description,
+		"url":              meta.URL,
+		"mainEntityOfPage": map[string]any{"@type": "WebPage", "@id": meta.URL},
+		"datePublished":    meta.Published,
+		"dateModified":     meta.Modified,
+		"author":           map[string]any{"@type": "Person", "name": meta.Author},
+		"publisher":        map[string]any{"@type": "Organization", "name": site.Title},
+		"keywords":         strings.Join(article.Tags, ","),
+	}
+	if meta.Image != "" {
+		jsonLD["image"] = meta.Image
+	}
+	if article.Category != "" {
+		jsonLD["articleSection"] = article.Category
+	}
+	// json.Marshal 会转义 < > &，不会提前结束script标签
+	data, _ := json.Marshal(jsonLD)
+	meta.JSONLD = template.JS(data)
+	return meta
+}
+
+// absoluteURL 本地上传的图片是相对路径，分享时要完整地址
+func absoluteURL(path string) string {
+	if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return feed_ser.SiteURL() + "/" + strings.TrimPrefix(path, "/")
+}
+
+func isoTime(s string) string {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", s, time.Local)
+	if err != nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
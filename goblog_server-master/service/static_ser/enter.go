@@ -0,0 +1,4 @@
+package static_ser
+
+type StaticService struct {
+}
@@ -0,0 +1,290 @@
+package static_ser
+
+import (
+	"embed"
+	"fmt"
+	"gvb_server/config"
+	"gvb_server/global"
+	"gvb_server/models"
+	"gvb_server/service/article_ser"
+	"html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// 每页的文章数
+const pageSize = 10
+
+// pager 分页
+type pager struct {
+	Page  int
+	Total int
+	Prev  string
+	Next  string
+}
+
+// term 标签、分类
+type term struct {
+	Name  string
+	URL   string
+	Count int
+}
+
+// calendarMonth 按月归档
+type calendarMonth struct {
+	Month    string
+	Count    int
+	Articles []models.ArticleModel
+}
+
+// articleView 文章页，正文已经渲染成html
+type articleView struct {
+	models.ArticleModel
+	HTML template.HTML
+}
+
+type pageData struct {
+	Site        config.SiteInfo
+	Title       string
+	Description string
+	GeneratedAt string
+	Articles    []models.ArticleModel
+	Pager       pager
+	Article     *articleView
+	Terms       []term
+	Calendar    []calendarMonth
+}
+
+// exporter 一次导出
+type exporter struct {
+	dir         string
+	generatedAt string
+	templates   map[string]*template.Template
+	fileCount   int
+}
+
+// Export 把已发布的文章、标签页、分类页和归档页生成静态html，返回生成的文件数
+func (StaticService) Export(dir string) (int, error) {
+	e := &exporter{
+		dir:         dir,
+		generatedAt: time.Now().Format("2006-01-02 15:04:05"),
+		templates:   map[string]*template.Template{},
+	}
+	funcMap := template.FuncMap{
+		"tagURL":      tagURL,
+		"categoryURL": categoryURL,
+	}
+	for _, name := range []string{"list", "article", "terms", "calendar"} {
+		tpl, err := template.New(name).Funcs(funcMap).ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html")
+		if err != nil {
+			return 0, err
+		}
+		e.templates[name] = tpl
+	}
+
+	articleList, err := allArticles()
+	if err != nil {
+		return 0, err
+	}
+
+	// 首页
+	err = e.writeList("/", "", articleList)
+	if err != nil {
+		return e.fileCount, err
+	}
+
+	// 文章
+	articleService := article_ser.ArticleService{}
+	for _, article := range articleList {
+		view := &articleView{
+			ArticleModel: article,
+			HTML:         template.HTML(articleService.RenderHTML(article.Content)),
+		}
+		err = e.write("article", "/article/"+article.ID+"/", pageData{
+			Title:       article.Title,
+			Description: article.Abstract,
+			Article:     view,
+		})
+		if err != nil {
+			return e.fileCount, err
+		}
+	}
+
+	// 标签和分类
+	tagMap := map[string][]models.ArticleModel{}
+	categoryMap := map[string][]models.ArticleModel{}
+	for _, article := range articleList {
+		for _, tag := range article.Tags {
+			tagMap[tag] = append(tagMap[tag], article)
+		}
+		if article.Category != "" {
+			categoryMap[article.Category] = append(categoryMap[article.Category], article)
+		}
+	}
+	err = e.writeTerms("标签", "/tags/", tagMap, tagURL)
+	if err != nil {
+		return e.fileCount, err
+	}
+	err = e.writeTerms("分类", "/categories/", categoryMap, categoryURL)
+	if err != nil {
+		return e.fileCount, err
+	}
+
+	err = e.writeCalendar(articleList)
+	return e.fileCount, err
+}
+
+// allArticles 全部已发布的文章，按创建时间倒序
+func allArticles() (list []models.ArticleModel, err error) {
+	repository := article_ser.NewRepository()
+	option := article_ser.ListOption{PageInfo: models.PageInfo{Limit: 100, Sort: "created_at desc"}}
+	for option.Page = 1; ; option.Page++ {
+		_list, count, err := repository.List(option)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, _list...)
+		if len(_list) == 0 || len(list) >= count {
+			break
+		}
+	}
+	return list, nil
+}
+
+// writeList 文章列表，带分页，第一页是 base，后面是 base/page/2/
+func (e *exporter) writeList(base, title string, articleList []models.ArticleModel) error {
+	total := (len(articleList) + pageSize - 1) / pageSize
+	if total == 0 {
+		total = 1
+	}
+	pageURL := func(page int) string {
+		if page == 1 {
+			return base
+		}
+		return fmt.Sprintf("%spage/%d/", base, page)
+	}
+	for page := 1; page <= total; page++ {
+		start := (page - 1) * pageSize
+		end := start + pageSize
+		if end > len(articleList) {
+			end = len(articleList)
+		}
+		p := pager{Page: page, Total: total}
+		if page > 1 {
+			p.Prev = pageURL(page - 1)
+		}
+		if page < total {
+			p.Next = pageURL(page + 1)
+		}
+		err := e.write("list", pageURL(page), pageData{
+			Title:    title,
+			Articles: articleList[start:end],
+			Pager:    p,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTerms 标签或分类的汇总页，以及每个标签、分类下的文章列表
+func (e *exporter) writeTerms(name, base string, termMap map[string][]models.ArticleModel, termURL func(string) string) error {
+	var termList []term
+	for key, articleList := range termMap {
+		termList = append(termList, term{Name: key, URL: termURL(key), Count: len(articleList)})
+		err := e.writeList(termURL(key), fmt.Sprintf("%s：%s", name, key), articleList)
+		if err != nil {
+			return err
+		}
+	}
+	sort.Slice(termList, func(i, j int) bool {
+		if termList[i].Count != termList[j].Count {
+			return termList[i].Count > termList[j].Count
+		}
+		return termList[i].Name < termList[j].Name
+	})
+	return e.write("terms", base, pageData{Title: name, Terms: termList})
+}
+
+// writeCalendar 按月归档，每月的文章数来自文章日历
+func (e *exporter) writeCalendar(articleList []models.ArticleModel) error {
+	var calendar []calendarMonth
+	if len(articleList) > 0 {
+		format := "2006-01-02 15:04:05"
+		start, _ := time.ParseInLocation(format, articleList[len(articleList)-1].CreatedAt, time.Local)
+		dateCount, err := article_ser.NewRepository().Calendar(start, time.Now())
+		if err != nil {
+			return err
+		}
+		monthCount := map[string]int{}
+		for date, count := range dateCount {
+			if len(date) >= 7 {
+				monthCount[date[:7]] += count
+			}
+		}
+		for _, article := range articleList {
+			if len(article.CreatedAt) < 7 {
+				continue
+			}
+			month := article.CreatedAt[:7]
+			if len(calendar) == 0 || calendar[len(calendar)-1].Month != month {
+				calendar = append(calendar, calendarMonth{Month: month, Count: monthCount[month]})
+			}
+			calendar[len(calendar)-1].Articles = append(calendar[len(calendar)-1].Articles, article)
+		}
+	}
+	return e.write("calendar", "/calendar/", pageData{Title: "归档", Calendar: calendar})
+}
+
+// write 渲染页面，写到 dir/path/index.html
+func (e *exporter) write(tpl, path string, data pageData) error {
+	data.Site = global.Config.SiteInfo
+	data.GeneratedAt = e.generatedAt
+	filePath := filepath.Join(e.dir, filepath.FromSlash(unescapePath(path)), "index.html")
+	err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	err = e.templates[tpl].ExecuteTemplate(file, "layout", data)
+	if err != nil {
+		return fmt.Errorf("生成 %s 失败 %s", path, err)
+	}
+	e.fileCount++
+	return nil
+}
+
+// termPath 标签、分类名作为一级目录，去掉路径分隔符
+func termPath(name string) string {
+	name = strings.NewReplacer("/", "-", "\\", "-", "..", "-").Replace(strings.TrimSpace(name))
+	return url.PathEscape(name)
+}
+
+func tagURL(tag string) string {
+	return "/tag/" + termPath(tag) + "/"
+}
+
+func categoryURL(category string) string {
+	return "/category/" + termPath(category) + "/"
+}
+
+// unescapePath 链接里是转义后的路径，磁盘上用原始的名字
+func unescapePath(path string) string {
+	s, err := url.PathUnescape(path)
+	if err != nil {
+		return path
+	}
+	return s
+}
@@ -0,0 +1,100 @@
+package ahocorasick
+
+import "strings"
+
+// Matcher Aho-Corasick多模式匹配，一次扫描找出文本里出现的所有词，不区分大小写
+type Matcher struct {
+	nodeList []node
+}
+
+type node struct {
+	next   map[rune]int
+	fail   int
+	output []int // 以这个节点结尾的词，包括fail链上的
+	word   string
+}
+
+// New 用词典构建匹配器，空词会被忽略
+func New(wordList []string) *Matcher {
+	m := &Matcher{nodeList: []node{{next: map[rune]int{}}}}
+	for _, word := range wordList {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word == "" {
+			continue
+		}
+		m.insert(word)
+	}
+	m.build()
+	return m
+}
+
+func (m *Matcher) insert(word string) {
+	cur := 0
+	for _, r := range word {
+		next, ok := m.nodeList[cur].next[r]
+		if !ok {
+			m.nodeList = append(m.nodeList, node{next: map[rune]int{}})
+			next = len(m.nodeList) - 1
+			m.nodeList[cur].next[r] = next
+		}
+		cur = next
+	}
+	if m.nodeList[cur].word == "" {
+		m.nodeList[cur].word = word
+		m.nodeList[cur].output = append(m.nodeList[cur].output, cur)
+	}
+}
+
+// build 按层遍历建立失配指针
+func (m *Matcher) build() {
+	var queue []int
+	for _, child := range m.nodeList[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for r, child := range m.nodeList[cur].next {
+			fail := m.nodeList[cur].fail
+			for fail != 0 {
+				if _, ok := m.nodeList[fail].next[r]; ok {
+					break
+				}
+				fail = m.nodeList[fail].fail
+			}
+			if next, ok := m.nodeList[fail].next[r]; ok && next != child {
+				m.nodeList[child].fail = next
+			}
+			m.nodeList[child].output = append(m.nodeList[child].output, m.nodeList[m.nodeList[child].fail].output...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+// Match 文本里出现的词，按第一次出现的顺序去重
+func (m *Matcher) Match(text string) (wordList []string) {
+	var seen = map[int]bool{}
+	cur := 0
+	for _, r := range strings.ToLower(text) {
+		for cur != 0 {
+			if _, ok := m.nodeList[cur].next[r]; ok {
+				break
+			}
+			cur = m.nodeList[cur].fail
+		}
+		cur = m.nodeList[cur].next[r]
+		for _, out := range m.nodeList[cur].output {
+			if seen[out] {
+				continue
+			}
+			seen[out] = true
+			wordList = append(wordList, m.nodeList[out].word)
+		}
+	}
+	return wordList
+}
+
+// Empty 词典是否为空
+func (m *Matcher) Empty() bool {
+	return len(m.nodeList) == 1
+}
@@ -0,0 +1,34 @@
+package ahocorasick
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	m := New([]string{"he", "she", "his", "hers", "广告", "加微信", ""})
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{"ushers", []string{"she", "he", "hers"}},
+		{"This is HIS", []string{"his"}},
+		{"欢迎加微信看广告，广告", []string{"加微信", "广告"}},
+		{"nothing", nil},
+	}
+	for _, c := range cases {
+		got := m.Match(c.text)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Match(%q) = %v, want %v", c.text, got, c.want)
+		}
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	if !New(nil).Empty() || New([]string{"a"}).Empty() {
+		t.Fatal("Empty")
+	}
+	if New(nil).Match("abc") != nil {
+		t.Fatal("empty matcher matched")
+	}
+}
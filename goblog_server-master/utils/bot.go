@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 常见爬虫和脚本的ua关键字
+var botKeywordList = []string{
+	"bot", "spider", "crawl", "slurp", "bingpreview", "mediapartners",
+	"facebookexternalhit", "embedly", "quora link preview", "whatsapp", "telegram",
+	"curl", "wget", "python-requests", "python-urllib", "go-http-client", "java/",
+	"okhttp", "axios", "postman", "headlesschrome", "phantomjs", "lighthouse",
+}
+
+// IsBot 根据ua判断是否为爬虫，没有ua的也算
+func IsBot(userAgent string) bool {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return true
+	}
+	for _, keyword := range botKeywordList {
+		if strings.Contains(ua, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// VisitorKey 区分访客，登录用户按用户id，未登录按ip和ua的指纹
+func VisitorKey(userID uint, ip, userAgent string) string {
+	if userID != 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	return "anon:" + MD5([]byte(ip+"|"+userAgent))
+}
@@ -0,0 +1,97 @@
+package diff
+
+import "strings"
+
+type Op string
+
+const (
+	Equal  Op = "equal"  // 未改动
+	Insert Op = "insert" // 新增的行
+	Delete Op = "delete" // 删除的行
+)
+
+// Line 一行差异，OldNo和NewNo为行号，从1开始，不存在时为0
+type Line struct {
+	Op      Op     `json:"op"`
+	OldNo   int    `json:"old_no"`
+	NewNo   int    `json:"new_no"`
+	Content string `json:"content"`
+}
+
+// Lines 按行对比两段文本，基于最长公共子序列
+func Lines(oldText, newText string) []Line {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+
+	// 去掉相同的头尾，减少计算量
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []Line
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, Line{Op: Equal, OldNo: i + 1, NewNo: i + 1, Content: a[i]})
+	}
+	lines = append(lines, lcs(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix, prefix)...)
+	for i := suffix; i > 0; i-- {
+		oldNo, newNo := len(a)-i, len(b)-i
+		lines = append(lines, Line{Op: Equal, OldNo: oldNo + 1, NewNo: newNo + 1, Content: a[oldNo]})
+	}
+	return lines
+}
+
+func lcs(a, b []string, oldOffset, newOffset int) (lines []Line) {
+	n, m := len(a), len(b)
+	// dp[i][j] a[i:]和b[j:]的最长公共子序列长度
+	dp := make([][]int32, n+1)
+	for i := range dp {
+		dp[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else if dp[i+1][j] >= dp[i][j+1] {
+				dp[i][j] = dp[i+1][j]
+			} else {
+				dp[i][j] = dp[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, Line{Op: Equal, OldNo: oldOffset + i + 1, NewNo: newOffset + j + 1, Content: a[i]})
+			i++
+			j++
+		case dp[i+1][j] >= dp[i][j+1]:
+			lines = append(lines, Line{Op: Delete, OldNo: oldOffset + i + 1, Content: a[i]})
+			i++
+		default:
+			lines = append(lines, Line{Op: Insert, NewNo: newOffset + j + 1, Content: b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		lines = append(lines, Line{Op: Delete, OldNo: oldOffset + i + 1, Content: a[i]})
+	}
+	for ; j < m; j++ {
+		lines = append(lines, Line{Op: Insert, NewNo: newOffset + j + 1, Content: b[j]})
+	}
+	return lines
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
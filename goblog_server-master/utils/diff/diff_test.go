@@ -0,0 +1,29 @@
+package diff
+
+import "testing"
+
+func TestLines(t *testing.T) {
+	lines := Lines("a\nb\nc\nd", "a\nc\nd\ne")
+	want := []Line{
+		{Op: Equal, OldNo: 1, NewNo: 1, Content: "a"},
+		{Op: Delete, OldNo: 2, Content: "b"},
+		{Op: Equal, OldNo: 3, NewNo: 2, Content: "c"},
+		{Op: Equal, OldNo: 4, NewNo: 3, Content: "d"},
+		{Op: Insert, NewNo: 4, Content: "e"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d: %+v", len(lines), len(want), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d: got %+v, want %+v", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestLinesEmpty(t *testing.T) {
+	lines := Lines("", "x")
+	if len(lines) != 1 || lines[0].Op != Insert {
+		t.Fatalf("got %+v", lines)
+	}
+}
@@ -0,0 +1,22 @@
+package utils
+
+import "regexp"
+
+// @前面必须是开头或空白，邮箱之类的 a@example.com 不算
+var mentionRegexp = regexp.MustCompile(`(?:^|\s)@([^\s@，。！？、：；,.!?:;]+)`)
+
+// ParseMentions 内容里@的用户名或昵称，去重，最多limit个
+func ParseMentions(content string, limit int) (nameList []string) {
+	var seen = map[string]bool{}
+	for _, match := range mentionRegexp.FindAllStringSubmatch(content, -1) {
+		if seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+		nameList = append(nameList, match[1])
+		if len(nameList) >= limit {
+			break
+		}
+	}
+	return nameList
+}
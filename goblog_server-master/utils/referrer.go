@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"net/url"
+	"strings"
+)
+
+// 搜索引擎和站内搜索常用的关键字参数
+var keywordParamList = []string{"q", "wd", "word", "query", "keyword", "key", "p", "text"}
+
+// ParseReferrer 从来源地址中解析出来源域名和搜索关键字，解析不了的返回空
+func ParseReferrer(referrer string) (host, keyword string) {
+	u, err := url.Parse(strings.TrimSpace(referrer))
+	if err != nil || u.Host == "" {
+		return "", ""
+	}
+	host = strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	query := u.Query()
+	for _, param := range keywordParamList {
+		keyword = strings.TrimSpace(query.Get(param))
+		if keyword != "" {
+			break
+		}
+	}
+	// 关键字过长的多半不是搜索词
+	if len([]rune(keyword)) > 50 {
+		keyword = string([]rune(keyword)[:50])
+	}
+	return host, keyword
+}
@@ -0,0 +1,161 @@
+package timeseries
+
+import (
+	"errors"
+	"time"
+)
+
+// Granularity 统计的时间粒度
+type Granularity string
+
+const (
+	Hour Granularity = "hour"
+	Day  Granularity = "day"
+	Week Granularity = "week" // 周一为一周的开始
+)
+
+// 每种粒度最多的区间数，避免一次查太长的时间
+var maxBucketMap = map[Granularity]int{
+	Hour: 31 * 24,
+	Day:  366,
+	Week: 157,
+}
+
+func (g Granularity) IsValid() bool {
+	_, ok := maxBucketMap[g]
+	return ok
+}
+
+// Truncate 对齐到所在区间的起点，按t所在的时区
+func (g Granularity) Truncate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch g {
+	case Hour:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	case Week:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// Next 下一个区间的起点
+func (g Granularity) Next(t time.Time) time.Time {
+	switch g {
+	case Hour:
+		return t.Add(time.Hour)
+	case Week:
+		return t.AddDate(0, 0, 7)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// Format 区间的显示格式
+func (g Granularity) Format(t time.Time) string {
+	if g == Hour {
+		return t.Format("2006-01-02 15:00")
+	}
+	return t.Format("2006-01-02")
+}
+
+// Range from到to之间所有区间的起点，from、to所在的区间都包含在内
+func (g Granularity) Range(from, to time.Time) ([]time.Time, error) {
+	if to.Before(from) {
+		return nil, errors.New("结束时间不能早于开始时间")
+	}
+	var list []time.Time
+	for t := g.Truncate(from); !t.After(to); t = g.Next(t) {
+		list = append(list, t)
+		if len(list) > maxBucketMap[g] {
+			return nil, errors.New("时间范围太大，请缩小范围或使用更大的粒度")
+		}
+	}
+	return list, nil
+}
+
+// Series 对齐后的计数序列，没有数据的区间为0
+type Series struct {
+	Granularity Granularity
+	TimeList    []time.Time
+	index       map[int64]int
+}
+
+// NewSeries 按区间起点建立序列
+func NewSeries(g Granularity, timeList []time.Time) *Series {
+	s := &Series{Granularity: g, TimeList: timeList, index: map[int64]int{}}
+	for i, t := range timeList {
+		s.index[t.Unix()] = i
+	}
+	return s
+}
+
+// BoundList 所有区间的边界，比区间多一个，最后一个是最后一个区间的终点
+func (s *Series) BoundList() []time.Time {
+	if len(s.TimeList) == 0 {
+		return nil
+	}
+	list := append([]time.Time{}, s.TimeList...)
+	return append(list, s.Granularity.Next(s.TimeList[len(s.TimeList)-1]))
+}
+
+// Labels 每个区间的显示文本
+func (s *Series) Labels() []string {
+	var list = make([]string, 0, len(s.TimeList))
+	for _, t := range s.TimeList {
+		list = append(list, s.Granularity.Format(t))
+	}
+	return list
+}
+
+// Count 把时间点落到区间里计数，loc为统计用的时区
+func (s *Series) Count(timeList []time.Time, loc *time.Location) []int {
+	var countList = make([]int, len(s.TimeList))
+	for _, t := range timeList {
+		i, ok := s.index[s.Granularity.Truncate(t.In(loc)).Unix()]
+		if ok {
+			countList[i]++
+		}
+	}
+	return countList
+}
+
+// ParseTime 解析查询参数里的时间，支持日期和日期时间两种格式
+// 只有日期时，end为true取当天最后一刻，否则取当天0点
+func ParseTime(value string, loc *time.Location, end bool) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02 15:04:05", value, loc)
+	if err == nil {
+		return t, nil
+	}
+	t, err = time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return t, errors.New("时间格式错误，请使用 2006-01-02 或 2006-01-02 15:04:05")
+	}
+	if end {
+		t = t.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return t, nil
+}
+
+// ParseRange 解析开始和结束时间，结束时间默认为现在，开始时间默认为结束时间往前days天
+func ParseRange(from, to string, loc *time.Location, days int) (start, end time.Time, err error) {
+	end = time.Now().In(loc)
+	if to != "" {
+		end, err = ParseTime(to, loc, true)
+		if err != nil {
+			return
+		}
+	}
+	start = Day.Truncate(end.AddDate(0, 0, 1-days))
+	if from != "" {
+		start, err = ParseTime(from, loc, false)
+		if err != nil {
+			return
+		}
+	}
+	if end.Before(start) {
+		err = errors.New("结束时间不能早于开始时间")
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package timeseries
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeekRange(t *testing.T) {
+	loc := time.UTC
+	from := time.Date(2024, 1, 3, 10, 0, 0, 0, loc) // 周三
+	to := time.Date(2024, 1, 15, 0, 0, 0, 0, loc)   // 周一
+	list, err := Week.Range(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2024-01-01", "2024-01-08", "2024-01-15"}
+	labels := NewSeries(Week, list).Labels()
+	if len(labels) != len(want) {
+		t.Fatalf("got %v, want %v", labels, want)
+	}
+	for i := range want {
+		if labels[i] != want[i] {
+			t.Errorf("bucket %d: got %s, want %s", i, labels[i], want[i])
+		}
+	}
+}
+
+func TestCountZeroFill(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, loc)
+	list, _ := Day.Range(from, from.AddDate(0, 0, 2))
+	series := NewSeries(Day, list)
+	// UTC 2023-12-31 16:00 在东八区已经是 1 月 1 日
+	countList := series.Count([]time.Time{
+		time.Date(2023, 12, 31, 16, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 3, 1, 0, 0, 0, loc),
+		time.Date(2024, 1, 9, 0, 0, 0, 0, loc),
+	}, loc)
+	want := []int{1, 0, 1}
+	for i := range want {
+		if countList[i] != want[i] {
+			t.Errorf("bucket %d: got %d, want %d", i, countList[i], want[i])
+		}
+	}
+}
+
+func TestRangeTooLarge(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := Hour.Range(from, from.AddDate(0, 2, 0))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+}